@@ -0,0 +1,184 @@
+/*
+Package africastalking provides a single entry point to all Africa's Talking services.
+
+A Client is configured once with your credentials, environment and HTTP client and
+hands out service clients (SMS, Voice, Airtime, Data) that share that configuration.
+
+Africa's Talking API Reference: https://developers.africastalking.com/docs/
+*/
+package africastalking
+
+import (
+	"net/http"
+
+	"github.com/edwinwalela/africastalking-go/pkg/airtime"
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/data"
+	"github.com/edwinwalela/africastalking-go/pkg/sms"
+	"github.com/edwinwalela/africastalking-go/pkg/voice"
+)
+
+// DefaultTimeout bounds requests made through an HTTP client created by WithTransport
+// and by service clients that have no HTTP client configured
+const DefaultTimeout = core.DefaultTimeout
+
+// Option configures the root Client
+type Option func(*Client)
+
+// Client holds the configuration shared by every Africa's Talking service client
+type Client struct {
+	apiKey     string            // API Key provided by Africa's talking
+	username   string            // Your Africa's talking application username
+	isSandbox  bool              // Specifies whether to use sandbox or live environment
+	httpClient *http.Client      // HTTP client shared by all service clients, nil uses each service's default
+	endpoints  core.Resolver     // Resolves API URLs for all service clients, nil uses Africa's Talking's hosts
+	retry      *core.RetryPolicy // Retry policy shared by all service clients
+	limiter    *core.RateLimiter // Rate limiter shared by all service clients
+	region     string            // Default region used to normalise phone numbers, empty disables normalisation
+
+	sms     *sms.Client
+	voice   *voice.Client
+	airtime *airtime.Client
+	data    *data.Client
+}
+
+// WithCredentials sets the application username and API key used for every request
+func WithCredentials(username, apiKey string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.apiKey = apiKey
+	}
+}
+
+// WithSandbox routes every request to the Africa's Talking sandbox environment
+func WithSandbox() Option {
+	return func(c *Client) {
+		c.isSandbox = true
+	}
+}
+
+// WithHTTPClient sets the HTTP client shared by all service clients
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the round tripper used by the shared HTTP client.
+// Requests made through the resulting client time out after DefaultTimeout
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Transport: transport, Timeout: DefaultTimeout}
+	}
+}
+
+// WithEndpoints resolves the API URLs of every service through resolver,
+// e.g core.AllHosts(server.URL) to send all requests to an httptest.Server
+func WithEndpoints(resolver core.Resolver) Option {
+	return func(c *Client) {
+		c.endpoints = resolver
+	}
+}
+
+// WithRetryPolicy sets the retry policy shared by all service clients, nil disables retries.
+// Defaults to core.DefaultRetryPolicy, see core.Do for which failures are retried
+func WithRetryPolicy(policy *core.RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRateLimiter throttles the requests of every service client through limiter,
+// keeping all goroutines sharing the client under the account's request quota
+func WithRateLimiter(limiter *core.RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithRegion normalises the phone numbers of every request to E.164 before sending,
+// reading local numbers such as "0712 345 678" as numbers of the ISO country code region e.g "KE"
+func WithRegion(region string) Option {
+	return func(c *Client) {
+		c.region = region
+	}
+}
+
+// New creates a Client configured with the given options
+func New(opts ...Option) *Client {
+	c := &Client{retry: core.DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.sms = &sms.Client{
+		ApiKey:    c.apiKey,
+		Username:  c.username,
+		IsSandbox: c.isSandbox,
+		Client:    c.httpClient,
+		Endpoints: c.endpoints,
+		Retry:     c.retry,
+		Limiter:   c.limiter,
+		Region:    c.region,
+	}
+	c.voice = &voice.Client{
+		ApiKey:    c.apiKey,
+		Username:  c.username,
+		IsSandbox: c.isSandbox,
+		Client:    c.httpClient,
+		Endpoints: c.endpoints,
+		Retry:     c.retry,
+		Limiter:   c.limiter,
+		Region:    c.region,
+	}
+	c.airtime = &airtime.Client{
+		ApiKey:    c.apiKey,
+		Username:  c.username,
+		IsSandbox: c.isSandbox,
+		Client:    c.httpClient,
+		Endpoints: c.endpoints,
+		Retry:     c.retry,
+		Limiter:   c.limiter,
+		Region:    c.region,
+	}
+	c.data = &data.Client{
+		ApiKey:    c.apiKey,
+		Username:  c.username,
+		IsSandbox: c.isSandbox,
+		Client:    c.httpClient,
+		Endpoints: c.endpoints,
+		Retry:     c.retry,
+		Limiter:   c.limiter,
+		Region:    c.region,
+	}
+	return c
+}
+
+// Username returns the application username the client was configured with
+func (c *Client) Username() string {
+	return c.username
+}
+
+// IsSandbox reports whether the client targets the sandbox environment
+func (c *Client) IsSandbox() bool {
+	return c.isSandbox
+}
+
+// SMS returns the SMS service client
+func (c *Client) SMS() *sms.Client {
+	return c.sms
+}
+
+// Voice returns the Voice service client
+func (c *Client) Voice() *voice.Client {
+	return c.voice
+}
+
+// Airtime returns the Airtime service client
+func (c *Client) Airtime() *airtime.Client {
+	return c.airtime
+}
+
+// Data returns the Mobile Data service client
+func (c *Client) Data() *data.Client {
+	return c.data
+}
@@ -0,0 +1,105 @@
+package africastalking
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/data"
+	"github.com/edwinwalela/africastalking-go/pkg/sms"
+	"github.com/edwinwalela/africastalking-go/pkg/voice"
+)
+
+func TestNewSharesConfiguration(t *testing.T) {
+	httpClient := &http.Client{}
+	client := New(
+		WithCredentials("sandbox", "api-key"),
+		WithSandbox(),
+		WithHTTPClient(httpClient),
+	)
+
+	if client.SMS().Username != "sandbox" || client.SMS().ApiKey != "api-key" || !client.SMS().IsSandbox {
+		t.Fatalf("sms client not configured from root client: %+v", client.SMS())
+	}
+	if client.Voice().Username != "sandbox" || !client.Voice().IsSandbox {
+		t.Fatalf("voice client not configured from root client: %+v", client.Voice())
+	}
+	if client.Airtime().Client != httpClient {
+		t.Fatalf("expected airtime client to share the configured http client")
+	}
+	if client.Voice().Client != httpClient {
+		t.Fatalf("expected voice client to share the configured http client")
+	}
+	if client.Data().ApiKey != "api-key" {
+		t.Fatalf("expected data apiKey='api-key' got apiKey='%s'", client.Data().ApiKey)
+	}
+	if client.SMS().Retry == nil || client.SMS().Retry != client.Data().Retry {
+		t.Fatalf("expected services to share the default retry policy")
+	}
+	if New(WithRetryPolicy(nil)).Airtime().Retry != nil {
+		t.Fatalf("expected WithRetryPolicy(nil) to disable retries")
+	}
+	if client.SMS() != client.SMS() {
+		t.Fatalf("expected SMS() to return the same client on every call")
+	}
+}
+
+func TestWithEndpoints(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("apiKey") != "api-key" {
+			t.Errorf("expected apiKey header to be set")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version1/messaging":
+			w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 1/1","Recipients":[{"statusCode":101,"number":"+254700000001","status":"Success"}]}}`))
+		case "/call":
+			w.Write([]byte(`{"entries":[],"errorMessage":"None"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(
+		WithCredentials("sandbox", "api-key"),
+		WithEndpoints(core.AllHosts(server.URL)),
+	)
+
+	if _, err := client.SMS().SendBulk(&sms.BulkRequest{To: []string{"+254700000001"}, Message: "Hello AT"}); err != nil {
+		t.Fatalf("bulk sms request failed: %s", err.Error())
+	}
+	if _, err := client.SMS().SendPremium(&sms.PremiumRequest{To: []string{"+254700000001"}, Message: "Hello AT"}); err != nil {
+		t.Fatalf("premium sms request failed: %s", err.Error())
+	}
+	if _, err := client.Voice().Call(&voice.CallRequest{To: []string{"+254700000001"}}); err != nil {
+		t.Fatalf("call request failed: %s", err.Error())
+	}
+	expected := []string{"/version1/messaging", "/version1/messaging", "/call"}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Fatalf("expected path='%s' got path='%s'", path, paths[i])
+		}
+	}
+}
+
+func TestDataUsesClientUsername(t *testing.T) {
+	var body data.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"entries":[]}`))
+	}))
+	defer server.Close()
+
+	client := New(WithCredentials("sandbox", "api-key"), WithEndpoints(core.AllHosts(server.URL)))
+	request := &data.Request{ProductName: "Mobile Data", Recipients: []data.Recipient{{PhoneNumber: "+254700000001"}}}
+	if _, err := client.Data().Send(request); err != nil {
+		t.Fatalf("data request failed: %s", err.Error())
+	}
+	if body.Username != "sandbox" || request.Username != "" {
+		t.Fatalf("expected the client's username to be sent got username='%s'", body.Username)
+	}
+}
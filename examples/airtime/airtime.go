@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/edwinwalela/africastalking-go/pkg/airtime"
+	"github.com/edwinwalela/africastalking-go/pkg/core"
 )
 
 func main() {
@@ -17,9 +18,8 @@ func main() {
 
 	// Define a recipient to be topped up with airtime
 	recipient := airtime.Recipient{
-		PhoneNumber: "+25470000000001",
-		Amount:      10.00,
-		Currency:    airtime.KES,
+		PhoneNumber: "+254700000001",
+		Amount:      core.MustNewMoney(airtime.KES, 10),
 	}
 
 	// Define the request body for the top up request
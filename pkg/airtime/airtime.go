@@ -1,27 +1,25 @@
 /*
-Package airtime sends airtime
+Package airtime sends airtime and looks up the status of airtime transactions
 
 Africa's Talking API Reference: https://developers.africastalking.com/docs/airtime/sending
+
+Query Transaction API Reference: https://developers.africastalking.com/docs/airtime/query_transaction
 */
 package airtime
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strconv"
-	"strings"
-)
 
-const (
-	sandboxURL = "https://api.sandbox.africastalking.com/version1/airtime/send"
-	liveURL    = "https://api.africastalking.com/version1/airtime/send"
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
 )
 
+const sendPath = "/version1/airtime/send"
+
 const (
 	KES = "KES" // Kenya Currency Code
 	UGX = "UGX" // Uganda Currency Code
@@ -33,55 +31,60 @@ const (
 	ZMW = "ZMW" // Zambia  Currency Code
 	RWF = "RWF" // Rwanda Currency Code
 	GHS = "GHS" // Ghana Currency Code
-	XOF = "XOF" // Senegal, Ivory Coast and Cameroon  Currency Code
+	XOF = "XOF" // Senegal and Ivory Coast Currency Code
+	XAF = "XAF" // Cameroon Currency Code
 )
 
 // Recipient represent the target user to receive airtime
 type Recipient struct {
-	PhoneNumber string  // The number to be topped up "+254xxxxxxx"
-	Amount      float64 // Value of airtime to send
-	Currency    string  // Currency code of the amount e.g KES,UGX,TZS,NGN,ETB,MWK,ZMW,ZAR
+	PhoneNumber string     // The number to be topped up "+254xxxxxxx"
+	Amount      core.Money // Value of airtime to send including its currency e.g core.MustNewMoney(airtime.KES, 10)
 }
 
 // Request represents the request body for the Africa's talking airtime request
 type Request struct {
-	Recipients []Recipient // Targets to be topped up with airtime
+	Recipients     []Recipient // Targets to be topped up with airtime
+	IdempotencyKey string      // IdempotencyKey de-duplicates the request and allows it to be retried safely (optional)
 }
 
 // Transaction represents an individual airtime transaction result
 type Transaction struct {
-	PhoneNumber  string  // Phone number for this transaction
-	Amount       float64 // Value of airtime requested
-	Currency     string  // Currency code of the amount e.g KES,UGX,TZS,NGN,ETB,MWK,ZMW,ZAR
-	Discount     float64 // Discount applied to the requested airtime amount
-	Status       string  // Status of the request associated to this phone number
-	RequestId    string  // An identifier for the request to this phone number. Only generated if the status of the request is 'sent'
-	ErrorMessage string  // Error message for the request associated with this phone number
+	PhoneNumber  string     `json:"phoneNumber"`  // Phone number for this transaction
+	Amount       core.Money `json:"amount"`       // Value of airtime requested
+	Discount     core.Money `json:"discount"`     // Discount applied to the requested airtime amount
+	Status       string     `json:"status"`       // Status of the request associated to this phone number
+	RequestId    string     `json:"requestId"`    // An identifier for the request to this phone number. Only generated if the status of the request is 'sent'
+	ErrorMessage string     `json:"errorMessage"` // Error message for the request associated with this phone number
 }
 
 // Response represents the response from Africa's Talking API
 type Response struct {
-	NumSent       int           // Number of requests sent to the provider
-	TotalAmount   float64       // Total value of airtime sent to the provider
-	Currency      string        // Currency code of the total amount e.g KES,UGX,TZS,NGN,ETB,MWK,ZMW,ZAR
-	TotalDiscount float64       // Total discount applied on the airtime
-	Responses     []Transaction // A list of the airtime transaction results
-	ErrorMessage  string        // Error message if the entire request was rejected by the API
+	NumSent       int           `json:"numSent"`       // Number of requests sent to the provider
+	TotalAmount   core.Money    `json:"totalAmount"`   // Total value of airtime sent to the provider
+	TotalDiscount core.Money    `json:"totalDiscount"` // Total discount applied on the airtime
+	Responses     []Transaction `json:"responses"`     // A list of the airtime transaction results
+	ErrorMessage  string        `json:"errorMessage"`  // Error message if the entire request was rejected by the API
 }
 
-// Client represents the HTTP client responsible for communicating with Africa's Talking API
+// Client represents the HTTP client responsible for communicating with Africa's Talking API.
+// A Client is safe for concurrent use by multiple goroutines
 type Client struct {
-	ApiKey    string       // API Key provided by Africa's talking
-	Username  string       // Your Africa's talking application username
-	IsSandbox bool         // Specifies whether to use sandbox or live environment
-	Client    *http.Client // HTTP client for making requests to Africa's Talking API
+	ApiKey    string            // API Key provided by Africa's talking
+	Username  string            // Your Africa's talking application username
+	IsSandbox bool              // Specifies whether to use sandbox or live environment
+	Client    *http.Client      // HTTP client for making requests to Africa's Talking API. Defaults to core.DefaultHTTPClient
+	Endpoints core.Resolver     // Endpoints resolves API URLs, defaults to Africa's Talking's hosts for the environment
+	Retry     *core.RetryPolicy // Retry controls how transient failures are retried, nil disables retries
+	Limiter   *core.RateLimiter // Limiter throttles requests client-side, nil disables rate limiting
+	Region    string            // Region normalises phone numbers to E.164 before sending, reading local numbers as numbers of this ISO country code e.g "KE". Empty sends numbers as given
+	Limits    map[string]Limit  // Limits are the per-recipient airtime limits of each currency Send validates against, nil uses DefaultLimits. Must not be modified while the Client is in use
 }
 
 // formatRecipients converts the list of recipient to a JSON string
 func formatRecipients(recipients []Recipient) string {
 	str := "["
 	for i, recipient := range recipients {
-		str += fmt.Sprintf(`{"phoneNumber":"%s","amount":"%s %.2f"}`, recipient.PhoneNumber, recipient.Currency, recipient.Amount)
+		str += fmt.Sprintf(`{"phoneNumber":"%s","amount":"%s"}`, recipient.PhoneNumber, recipient.Amount)
 		if i != len(recipients)-1 {
 			str += ","
 		}
@@ -108,73 +111,52 @@ func setHeaders(request *http.Request, apiKey string) {
 
 // formatResponse maps response from Africa's Talking API to the internal Response type
 func formatResponse(response *http.Response) (Response, error) {
-	res := make(map[string]interface{})
-	decoder := json.NewDecoder(response.Body)
-	if err := decoder.Decode(&res); err != nil {
+	res := Response{}
+	if err := core.DecodeJSON(response, &res); err != nil {
 		return Response{}, err
 	}
-
-	responsesData := res["responses"].([]interface{})
-
-	responses := []Transaction{}
-
-	for _, v := range responsesData {
-		data := v.(map[string]interface{})
-		currency, amountStr, _ := strings.Cut(data["amount"].(string), " ")
-		_, discountStr, _ := strings.Cut(data["discount"].(string), " ")
-		amount, _ := strconv.ParseFloat(amountStr, 64)
-		discount, _ := strconv.ParseFloat(discountStr, 64)
-		response := Transaction{
-			PhoneNumber:  data["phoneNumber"].(string),
-			ErrorMessage: data["errorMessage"].(string),
-			Amount:       amount,
-			Currency:     currency,
-			Discount:     discount,
-			Status:       data["status"].(string),
-			RequestId:    data["requestId"].(string),
-		}
-		responses = append(responses, response)
+	if res.Responses == nil {
+		res.Responses = []Transaction{}
 	}
-
-	currency, totalAmountStr, _ := strings.Cut(res["totalAmount"].(string), " ")
-	totalAmount, _ := strconv.ParseFloat(totalAmountStr, 64)
-
-	_, totalDiscountStr, _ := strings.Cut(res["totalDiscount"].(string), " ")
-	totalDiscount, _ := strconv.ParseFloat(totalDiscountStr, 64)
-
-	return Response{
-		ErrorMessage:  res["errorMessage"].(string),
-		NumSent:       int(res["numSent"].(float64)),
-		TotalAmount:   totalAmount,
-		TotalDiscount: totalDiscount,
-		Currency:      currency,
-		Responses:     responses,
-	}, nil
+	return res, nil
 }
 
-// Send triggers Africa's Talking airtime API to send Airtime to the specified recipient(s)
+// Send triggers Africa's Talking airtime API to send Airtime to the specified recipient(s).
+// The request is checked with Request.Validate before it is sent
 func (c *Client) Send(request *Request) (Response, error) {
-	c.Client = &http.Client{}
+	return c.SendWithContext(context.Background(), request)
+}
+
+// SendWithContext is like Send but aborts the request when ctx is cancelled or its deadline expires
+func (c *Client) SendWithContext(ctx context.Context, request *Request) (Response, error) {
+	recipients, err := phonenumber.NormalizeEach(request.Recipients, c.Region, func(r *Recipient) *string { return &r.PhoneNumber })
+	if err != nil {
+		return Response{}, err
+	}
+	normalized := *request
+	normalized.Recipients = recipients
+	request = &normalized
+	if err := request.Validate(c.Limits); err != nil {
+		return Response{}, err
+	}
 	data := getRequestBody(request, c.Username)
-	url := liveURL
-	if c.IsSandbox {
-		url = sandboxURL
+	url := core.ResolveURL(c.Endpoints, core.HostAPI, c.IsSandbox, sendPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return Response{}, err
 	}
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data.Encode())))
 	setHeaders(req, c.ApiKey)
-	resp, err := c.Client.Do(req)
+	if request.IdempotencyKey != "" {
+		req.Header.Set(core.IdempotencyKeyHeader, request.IdempotencyKey)
+	}
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostAPI, sendPath))
 	if err != nil {
 		return Response{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return Response{}, err
-		}
-		res := string(bodyBytes)
-		return Response{}, errors.New(res)
+	if err := core.CheckResponse(resp); err != nil {
+		return Response{}, err
 	}
 	return formatResponse(resp)
 }
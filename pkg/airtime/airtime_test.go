@@ -1,8 +1,15 @@
 package airtime
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
 )
 
 func TestSendAirtime(t *testing.T) {
@@ -11,27 +18,25 @@ func TestSendAirtime(t *testing.T) {
 		ApiKey:    os.Getenv("AT_API_KEY"),
 		IsSandbox: true,
 	}
-	airtimeAmount := 10.00
+	airtimeAmount := core.MustNewMoney(KES, 10)
 	recipient1Phone := "+254700000001"
 	recipient2Phone := "+254700000002"
 
 	recipient1 := Recipient{
 		PhoneNumber: recipient1Phone,
 		Amount:      airtimeAmount,
-		Currency:    KES,
 	}
 
 	recipient2 := Recipient{
 		PhoneNumber: recipient2Phone,
 		Amount:      airtimeAmount,
-		Currency:    KES,
 	}
 
 	request := &Request{
 		Recipients: []Recipient{recipient2, recipient1},
 	}
 	response, err := client.Send(request)
-	expectedTotalAmount := float64(len(request.Recipients)) * airtimeAmount
+	expectedTotalAmount, _ := airtimeAmount.Mul(int64(len(request.Recipients)))
 
 	if err != nil {
 		t.Fatalf("airtime request failed: %s", err.Error())
@@ -40,9 +45,44 @@ func TestSendAirtime(t *testing.T) {
 		t.Fatalf("expected errorMessage='None' got errorMessage='%s'", response.ErrorMessage)
 	}
 	if response.TotalAmount != expectedTotalAmount {
-		t.Fatalf("expected totalAmount=%.2f got totalAmount=%.2f", expectedTotalAmount, response.TotalAmount)
+		t.Fatalf("expected totalAmount=%s got totalAmount=%s", expectedTotalAmount, response.TotalAmount)
 	}
 	if response.Responses[0].PhoneNumber != recipient1Phone {
 		t.Fatalf("expected recipientPhone=%s got recipientPhone=%s", recipient1Phone, response.Responses[0].PhoneNumber)
 	}
 }
+
+func TestFormatResponse(t *testing.T) {
+	body := `{"numSent":1,"totalAmount":"KES 10.0000","totalDiscount":null,"responses":[{"phoneNumber":"+254700000001","amount":"KES 10.0000","status":"Sent","requestId":"ATQid_1"}]}`
+	response, err := formatResponse(&http.Response{Body: io.NopCloser(strings.NewReader(body))})
+	if err != nil {
+		t.Fatalf("failed to format response: %s", err.Error())
+	}
+	if response.TotalAmount != core.MustNewMoney(KES, 10) || !response.TotalDiscount.IsZero() {
+		t.Fatalf("unexpected totals %+v", response)
+	}
+	if response.Responses[0].RequestId != "ATQid_1" || response.Responses[0].ErrorMessage != "" {
+		t.Fatalf("unexpected transaction %+v", response.Responses[0])
+	}
+}
+
+func TestFormatResponseInvalidAmount(t *testing.T) {
+	body := `{"numSent":1,"totalAmount":"KES ten","responses":[]}`
+	_, err := formatResponse(&http.Response{Body: io.NopCloser(strings.NewReader(body))})
+
+	var decodeErr *core.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected *core.DecodeError got %v", err)
+	}
+	if decodeErr.Body != body {
+		t.Fatalf("expected raw body to be kept got body='%s'", decodeErr.Body)
+	}
+}
+
+func TestPhoneNumberCurrencies(t *testing.T) {
+	for _, country := range phonenumber.Countries() {
+		if _, ok := DefaultLimits()[country.Currency]; !ok {
+			t.Fatalf("%s: currency '%s' has no airtime limits", country.Name, country.Currency)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package airtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+const findPath = "/query/transaction/find"
+
+// ErrTransactionNotFound is returned by FindTransaction when Africa's Talking has no record of the transaction
+var ErrTransactionNotFound = errors.New("airtime: transaction not found")
+
+// TransactionStatus is the status of an airtime transaction
+type TransactionStatus string
+
+const (
+	StatusSent    TransactionStatus = "Sent"    // The airtime was sent to the provider and is awaiting confirmation
+	StatusSuccess TransactionStatus = "Success" // The provider confirmed the recipient was topped up
+	StatusFailed  TransactionStatus = "Failed"  // The provider failed to top up the recipient
+)
+
+// IsFinal reports whether the transaction has completed and its status will no longer change
+func (s TransactionStatus) IsFinal() bool {
+	return s == StatusSuccess || s == StatusFailed
+}
+
+// TransactionDetails describes an airtime transaction found with FindTransaction
+type TransactionDetails struct {
+	TransactionId   string            `json:"transactionId"`   // TransactionId is the RequestId returned in Transaction
+	Status          TransactionStatus `json:"status"`          // Status of the transaction e.g StatusSuccess
+	Destination     string            `json:"destination"`     // Destination is the phone number that was topped up
+	DestinationType string            `json:"destinationType"` // DestinationType is the type of the destination e.g PhoneNumber
+	Value           core.Money        `json:"value"`           // Value of airtime sent
+	TransactionFee  core.Money        `json:"transactionFee"`  // TransactionFee charged for the transaction
+	Source          string            `json:"source"`          // Source the airtime was paid from
+	SourceType      string            `json:"sourceType"`      // SourceType is the type of the source e.g Wallet
+	Provider        string            `json:"provider"`        // Provider that processed the transaction
+	ProviderRefId   string            `json:"providerRefId"`   // ProviderRefId is the provider's reference for the transaction
+	ProductName     string            `json:"productName"`     // ProductName of the product the transaction was made through
+	Category        string            `json:"category"`        // Category of the transaction
+	Description     string            `json:"description"`     // Description of the outcome of the transaction
+	CreationTime    time.Time         `json:"creationTime"`    // CreationTime is when the transaction was created
+	TransactionDate time.Time         `json:"transactionDate"` // TransactionDate is when the provider processed the transaction
+}
+
+// findResponse is the wire format of the response from Africa's Talking query transaction API
+type findResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"errorMessage"`
+	Data         struct {
+		TransactionDetails
+		CreationTime    string `json:"creationTime"`
+		TransactionDate string `json:"transactionDate"`
+	} `json:"data"`
+}
+
+// transactionTimeLayouts are the formats dates are returned in, times without a zone are in UTC
+var transactionTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05"}
+
+// parseTransactionTime parses a transaction date, an empty value is the zero time
+func parseTransactionTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range transactionTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// isNotFound reports whether an error message from the query transaction API says the transaction does not exist
+func isNotFound(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "not found") || strings.Contains(message, "could not find")
+}
+
+/*
+FindTransaction looks up the status of the airtime transaction with requestId, the
+Transaction.RequestId returned when the airtime was sent. Use it to resolve transactions
+that are still StatusSent without waiting for the status callback.
+
+Returns an error wrapping ErrTransactionNotFound if the transaction does not exist, other
+failures reported by the API are returned with their error message.
+
+API Reference: https://developers.africastalking.com/docs/airtime/query_transaction
+*/
+func (c *Client) FindTransaction(ctx context.Context, requestId string) (TransactionDetails, error) {
+	if requestId == "" {
+		return TransactionDetails{}, errors.New("airtime: requestId is required")
+	}
+	query := url.Values{
+		"username":      {c.Username},
+		"transactionId": {requestId},
+	}
+	url := core.ResolveURL(c.Endpoints, core.HostAPI, c.IsSandbox, findPath) + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return TransactionDetails{}, err
+	}
+	setHeaders(req, c.ApiKey)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostAPI, findPath))
+	if err != nil {
+		return TransactionDetails{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := core.CheckResponse(resp); err != nil {
+		return TransactionDetails{}, err
+	}
+
+	res := findResponse{}
+	if err := core.DecodeJSON(resp, &res); err != nil {
+		return TransactionDetails{}, err
+	}
+	if res.Status != "Success" {
+		if isNotFound(res.ErrorMessage) {
+			return TransactionDetails{}, fmt.Errorf("%w: %s: %s", ErrTransactionNotFound, requestId, res.ErrorMessage)
+		}
+		return TransactionDetails{}, fmt.Errorf("airtime: finding transaction %s: %s", requestId, res.ErrorMessage)
+	}
+
+	details := res.Data.TransactionDetails
+	if details.CreationTime, err = parseTransactionTime(res.Data.CreationTime); err != nil {
+		return TransactionDetails{}, fmt.Errorf("airtime: invalid creationTime: %w", err)
+	}
+	if details.TransactionDate, err = parseTransactionTime(res.Data.TransactionDate); err != nil {
+		return TransactionDetails{}, fmt.Errorf("airtime: invalid transactionDate: %w", err)
+	}
+	return details, nil
+}
@@ -0,0 +1,65 @@
+package airtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestFindTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/query/transaction/find" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("username") != "sandbox" || r.Header.Get("apiKey") != "api-key" {
+			t.Errorf("expected credentials to be sent")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("transactionId") {
+		case "ATQid_1":
+			w.Write([]byte(`{"status":"Success","data":{"transactionId":"ATQid_1","status":"Success","destination":"+254700000001","value":"KES 10.0000","transactionFee":"KES 0.4000","provider":"Athena","creationTime":"2026-10-16 08:00:00","transactionDate":"2026-10-16T08:00:05Z"}}`))
+		case "ATQid_3":
+			w.Write([]byte(`{"status":"Failure","errorMessage":"Invalid username"}`))
+		default:
+			w.Write([]byte(`{"status":"Failure","errorMessage":"Could not find transaction"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{ApiKey: "api-key", Username: "sandbox", IsSandbox: true, Endpoints: core.AllHosts(server.URL)}
+
+	transaction, err := client.FindTransaction(context.Background(), "ATQid_1")
+	if err != nil {
+		t.Fatalf("find transaction failed: %s", err.Error())
+	}
+	if transaction.Status != StatusSuccess || !transaction.Status.IsFinal() {
+		t.Fatalf("expected final status='Success' got status='%s'", transaction.Status)
+	}
+	if transaction.Value != core.MustNewMoney(KES, 10) || transaction.Destination != "+254700000001" {
+		t.Fatalf("unexpected transaction %+v", transaction)
+	}
+
+	created := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	if !transaction.CreationTime.Equal(created) || !transaction.TransactionDate.Equal(created.Add(5*time.Second)) {
+		t.Fatalf("unexpected times creationTime=%s transactionDate=%s", transaction.CreationTime, transaction.TransactionDate)
+	}
+
+	if _, err := client.FindTransaction(context.Background(), "ATQid_2"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound got %v", err)
+	}
+	if _, err := client.FindTransaction(context.Background(), "ATQid_3"); err == nil || errors.Is(err, ErrTransactionNotFound) || !strings.Contains(err.Error(), "Invalid username") {
+		t.Fatalf("expected the API error message without ErrTransactionNotFound got %v", err)
+	}
+	if _, err := client.FindTransaction(context.Background(), ""); err == nil {
+		t.Fatalf("expected an error for an empty requestId")
+	}
+	if StatusSent.IsFinal() {
+		t.Fatalf("expected StatusSent not to be final")
+	}
+}
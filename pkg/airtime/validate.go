@@ -0,0 +1,119 @@
+package airtime
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
+)
+
+var (
+	ErrNoRecipients        = errors.New("airtime: request has no recipients")            // ErrNoRecipients is returned when a request has no recipients
+	ErrUnsupportedCurrency = errors.New("airtime: unsupported currency")                 // ErrUnsupportedCurrency is returned for currencies without limits
+	ErrAmountOutOfRange    = errors.New("airtime: amount outside the currency's limits") // ErrAmountOutOfRange is returned for amounts below Limit.Min or above Limit.Max
+	ErrCurrencyMismatch    = errors.New("airtime: currency does not match the country")  // ErrCurrencyMismatch is returned when the currency is not the one of the recipient's country
+	ErrDuplicateRecipient  = errors.New("airtime: duplicate phone number")               // ErrDuplicateRecipient is returned when a phone number appears more than once in a request
+)
+
+// Limit is the range of airtime that can be sent to a single recipient
+type Limit struct {
+	Min core.Money // Min is the smallest amount that can be sent
+	Max core.Money // Max is the largest amount that can be sent
+}
+
+// defaultLimits holds the per-recipient airtime limits of each currency documented by Africa's Talking, see DefaultLimits
+var defaultLimits = map[string]Limit{
+	KES: {Min: core.MustNewMoney(KES, 5), Max: core.MustNewMoney(KES, 10000)},
+	UGX: {Min: core.MustNewMoney(UGX, 50), Max: core.MustNewMoney(UGX, 200000)},
+	TZS: {Min: core.MustNewMoney(TZS, 500), Max: core.MustNewMoney(TZS, 200000)},
+	NGN: {Min: core.MustNewMoney(NGN, 50), Max: core.MustNewMoney(NGN, 5000)},
+	ETB: {Min: core.MustNewMoney(ETB, 5), Max: core.MustNewMoney(ETB, 5000)},
+	MWK: {Min: core.MustNewMoney(MWK, 100), Max: core.MustNewMoney(MWK, 50000)},
+	ZAR: {Min: core.MustNewMoney(ZAR, 5), Max: core.MustNewMoney(ZAR, 1000)},
+	ZMW: {Min: core.MustNewMoney(ZMW, 2), Max: core.MustNewMoney(ZMW, 1000)},
+	RWF: {Min: core.MustNewMoney(RWF, 100), Max: core.MustNewMoney(RWF, 40000)},
+	GHS: {Min: core.MustNewMoney(GHS, 1), Max: core.MustNewMoney(GHS, 500)},
+	XOF: {Min: core.MustNewMoney(XOF, 100), Max: core.MustNewMoney(XOF, 100000)},
+	XAF: {Min: core.MustNewMoney(XAF, 100), Max: core.MustNewMoney(XAF, 100000)},
+}
+
+// DefaultLimits returns a copy of the per-recipient airtime limits of each currency documented by Africa's Talking.
+// Adjust the copy and set it as Client.Limits if your account has been granted different limits
+func DefaultLimits() map[string]Limit {
+	limits := make(map[string]Limit, len(defaultLimits))
+	for currency, limit := range defaultLimits {
+		limits[currency] = limit
+	}
+	return limits
+}
+
+// RecipientError records why an individual recipient of a Request is invalid
+type RecipientError struct {
+	Index       int    // Index of the recipient in Request.Recipients
+	PhoneNumber string // PhoneNumber of the recipient
+	Err         error  // Err is the reason the recipient is invalid
+}
+
+// Error implements the error interface
+func (e *RecipientError) Error() string {
+	return fmt.Sprintf("airtime: recipient %d (%s): %s", e.Index, e.PhoneNumber, e.Err.Error())
+}
+
+// Unwrap returns the reason the recipient is invalid, for use with errors.Is and errors.As
+func (e *RecipientError) Unwrap() error {
+	return e.Err
+}
+
+// validateRecipient checks recipient's phone number, currency and amount, returning its E.164 number
+func validateRecipient(recipient Recipient, limits map[string]Limit) (string, error) {
+	number, err := phonenumber.Parse(recipient.PhoneNumber, "")
+	if err != nil {
+		return "", err
+	}
+	amount := recipient.Amount
+	limit, ok := limits[amount.Currency]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnsupportedCurrency, amount.Currency)
+	}
+	if amount.Currency != number.Country.Currency {
+		return "", fmt.Errorf("%w: %s numbers are topped up in %s not %s", ErrCurrencyMismatch, number.Country.Name, number.Country.Currency, amount.Currency)
+	}
+	if amount.Minor < limit.Min.Minor || amount.Minor > limit.Max.Minor {
+		return "", fmt.Errorf("%w: %s is not between %s and %s", ErrAmountOutOfRange, amount, limit.Min, limit.Max)
+	}
+	return number.E164(), nil
+}
+
+/*
+Validate checks the request before it is sent. Every recipient must have a phone number in
+international format, a currency with limits matching the phone number's country and an amount
+within those limits, and may only appear once. A nil limits uses DefaultLimits.
+
+The error joins a *RecipientError for every invalid recipient.
+Validate is called automatically by Client.Send with Client.Limits
+*/
+func (r *Request) Validate(limits map[string]Limit) error {
+	if len(r.Recipients) == 0 {
+		return ErrNoRecipients
+	}
+	if limits == nil {
+		limits = defaultLimits
+	}
+	errs := []error{}
+	seen := map[string]int{}
+	for i, recipient := range r.Recipients {
+		number, err := validateRecipient(recipient, limits)
+		if err == nil {
+			if first, ok := seen[number]; ok {
+				err = fmt.Errorf("%w: also recipient %d", ErrDuplicateRecipient, first)
+			} else {
+				seen[number] = i
+			}
+		}
+		if err != nil {
+			errs = append(errs, &RecipientError{Index: i, PhoneNumber: recipient.PhoneNumber, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
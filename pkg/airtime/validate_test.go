@@ -0,0 +1,111 @@
+package airtime
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestValidate(t *testing.T) {
+	request := &Request{Recipients: []Recipient{
+		{PhoneNumber: "+254700000001", Amount: core.MustNewMoney(KES, 10)},
+		{PhoneNumber: "+256772123456", Amount: core.MustNewMoney(UGX, 1000)},
+	}}
+	if err := request.Validate(nil); err != nil {
+		t.Fatalf("expected a valid request got %v", err)
+	}
+
+	if err := (&Request{}).Validate(nil); !errors.Is(err, ErrNoRecipients) {
+		t.Fatalf("expected ErrNoRecipients got %v", err)
+	}
+
+	request = &Request{Recipients: []Recipient{
+		{PhoneNumber: "+254700000001", Amount: core.MustNewMoney(KES, 10)},
+		{PhoneNumber: "+254700000002", Amount: core.Money{Currency: KES}},
+		{PhoneNumber: "+254700000003", Amount: core.MustNewMoney(UGX, 1000)},
+		{PhoneNumber: "+254 700 000 001", Amount: core.MustNewMoney(KES, 20)},
+		{PhoneNumber: "0700000004", Amount: core.MustNewMoney(KES, 10)},
+		{PhoneNumber: "+254700000005", Amount: core.MustNewMoney("USD", 10)},
+		{PhoneNumber: "+254700000006", Amount: core.MustNewMoney(KES, 10001)},
+	}}
+	err := request.Validate(nil)
+	expected := []struct {
+		index int
+		err   error
+	}{
+		{1, ErrAmountOutOfRange},
+		{2, ErrCurrencyMismatch},
+		{3, ErrDuplicateRecipient},
+		{4, nil},
+		{5, ErrUnsupportedCurrency},
+		{6, ErrAmountOutOfRange},
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %v", len(expected), err)
+	}
+	for i, e := range expected {
+		var recipientErr *RecipientError
+		if !errors.As(errs[i], &recipientErr) || recipientErr.Index != e.index {
+			t.Fatalf("expected error for recipient %d got %v", e.index, errs[i])
+		}
+		if e.err != nil && !errors.Is(recipientErr, e.err) {
+			t.Fatalf("recipient %d: expected %v got %v", e.index, e.err, recipientErr.Err)
+		}
+	}
+}
+
+func TestSendValidatesRequest(t *testing.T) {
+	calls := 0
+	client := &Client{
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("unexpected request")
+		})},
+	}
+	request := &Request{Recipients: []Recipient{{PhoneNumber: "+254700000001", Amount: core.MustNewMoney(KES, 0)}}}
+	if _, err := client.Send(request); !errors.Is(err, ErrAmountOutOfRange) {
+		t.Fatalf("expected ErrAmountOutOfRange got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected invalid request not to be sent")
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	limits := DefaultLimits()
+	limits[KES] = Limit{Min: core.MustNewMoney(KES, 1), Max: core.MustNewMoney(KES, 50000)}
+	if DefaultLimits()[KES] == limits[KES] {
+		t.Fatalf("expected DefaultLimits to return a copy")
+	}
+
+	request := &Request{Recipients: []Recipient{{PhoneNumber: "+254700000001", Amount: core.MustNewMoney(KES, 20000)}}}
+	if err := request.Validate(nil); !errors.Is(err, ErrAmountOutOfRange) {
+		t.Fatalf("expected ErrAmountOutOfRange with the default limits got %v", err)
+	}
+	if err := request.Validate(limits); err != nil {
+		t.Fatalf("expected the client's limits to allow the amount got %v", err)
+	}
+
+	calls := 0
+	client := &Client{
+		Limits: limits,
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("unexpected request")
+		})},
+	}
+	client.Send(request)
+	if calls != 1 {
+		t.Fatalf("expected the request to pass the client's limits and be sent")
+	}
+}
+
+// roundTripFunc adapts a function into an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,20 @@
+package core
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds requests made with DefaultHTTPClient
+const DefaultTimeout = 30 * time.Second
+
+// DefaultHTTPClient is used by every service client that has no HTTP client configured
+var DefaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// HTTPClient returns client, falling back to DefaultHTTPClient when it is nil
+func HTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return DefaultHTTPClient
+}
@@ -0,0 +1,16 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHTTPClient(t *testing.T) {
+	if HTTPClient(nil) != DefaultHTTPClient || DefaultHTTPClient.Timeout != DefaultTimeout {
+		t.Fatalf("expected nil to fall back to DefaultHTTPClient")
+	}
+	client := &http.Client{}
+	if HTTPClient(client) != client {
+		t.Fatalf("expected the configured client to be used")
+	}
+}
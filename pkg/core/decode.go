@@ -0,0 +1,37 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DecodeError is returned when a response from Africa's Talking API cannot be decoded
+type DecodeError struct {
+	Body string // Raw response body, kept for debugging
+	Err  error  // Underlying read or decode error
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("africastalking: decoding response: %s", e.Err.Error())
+}
+
+// Unwrap returns the underlying read or decode error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON reads the response body and decodes it into v.
+// Fields absent from the body keep their zero value
+func DecodeJSON(response *http.Response, v interface{}) error {
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return &DecodeError{Err: err}
+	}
+	if err := json.Unmarshal(bodyBytes, v); err != nil {
+		return &DecodeError{Body: string(bodyBytes), Err: err}
+	}
+	return nil
+}
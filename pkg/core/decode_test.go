@@ -0,0 +1,39 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	var res struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := DecodeJSON(newResponse(http.StatusOK, `{"status":"Success"}`), &res); err != nil {
+		t.Fatalf("decode failed: %s", err.Error())
+	}
+	if res.Status != "Success" || res.ErrorMessage != "" {
+		t.Fatalf("unexpected decoded value %+v", res)
+	}
+}
+
+func TestDecodeJSONInvalidBody(t *testing.T) {
+	var res struct {
+		NumSent int `json:"numSent"`
+	}
+	err := DecodeJSON(newResponse(http.StatusOK, `{"numSent":"two"}`), &res)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected *DecodeError got %T", err)
+	}
+	if decodeErr.Body != `{"numSent":"two"}` {
+		t.Fatalf("expected raw body to be kept got body='%s'", decodeErr.Body)
+	}
+	if !strings.Contains(err.Error(), "numSent") {
+		t.Fatalf("expected descriptive error got '%s'", err.Error())
+	}
+}
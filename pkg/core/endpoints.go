@@ -0,0 +1,99 @@
+package core
+
+import "strings"
+
+// Host identifies one of the Africa's Talking API hosts
+type Host string
+
+const (
+	HostAPI      Host = "api"      // Bulk SMS, fetching messages and airtime
+	HostContent  Host = "content"  // Premium SMS and subscriptions
+	HostVoice    Host = "voice"    // Voice calls and transfers
+	HostPayments Host = "payments" // Mobile data and payments
+)
+
+// liveBaseURLs are the base URLs of the live environment
+var liveBaseURLs = map[Host]string{
+	HostAPI:      "https://api.africastalking.com",
+	HostContent:  "https://content.africastalking.com",
+	HostVoice:    "https://voice.africastalking.com",
+	HostPayments: "https://payments.africastalking.com",
+}
+
+// sandboxBaseURLs are the base URLs of the sandbox environment
+var sandboxBaseURLs = map[Host]string{
+	HostAPI:      "https://api.sandbox.africastalking.com",
+	HostContent:  "https://api.sandbox.africastalking.com",
+	HostVoice:    "https://voice.sandbox.africastalking.com",
+	HostPayments: "https://payments.sandbox.africastalking.com",
+}
+
+// Endpoint names the API path on host independently of how its URL is resolved,
+// e.g "content/version1/messaging" for premium SMS. Rate limits are keyed by endpoint
+func Endpoint(host Host, path string) string {
+	return string(host) + path
+}
+
+// Resolver resolves the full URL of an API path on a host
+type Resolver interface {
+	URL(host Host, isSandbox bool, path string) string
+}
+
+// ResolverFunc adapts a function into a Resolver
+type ResolverFunc func(host Host, isSandbox bool, path string) string
+
+// URL calls f(host, isSandbox, path)
+func (f ResolverFunc) URL(host Host, isSandbox bool, path string) string {
+	return f(host, isSandbox, path)
+}
+
+// Endpoints overrides the base URL of each host, e.g to point at an httptest.Server or a proxy.
+// Empty fields fall back to Africa's Talking's own hosts for the environment
+type Endpoints struct {
+	API      string // Base URL for HostAPI e.g "https://api.africastalking.com"
+	Content  string // Base URL for HostContent
+	Voice    string // Base URL for HostVoice
+	Payments string // Base URL for HostPayments
+}
+
+// AllHosts returns Endpoints that send every request to baseURL
+func AllHosts(baseURL string) *Endpoints {
+	return &Endpoints{API: baseURL, Content: baseURL, Voice: baseURL, Payments: baseURL}
+}
+
+// URL implements Resolver
+func (e *Endpoints) URL(host Host, isSandbox bool, path string) string {
+	baseURL := ""
+	if e != nil {
+		switch host {
+		case HostAPI:
+			baseURL = e.API
+		case HostContent:
+			baseURL = e.Content
+		case HostVoice:
+			baseURL = e.Voice
+		case HostPayments:
+			baseURL = e.Payments
+		}
+	}
+	if baseURL == "" {
+		return DefaultURL(host, isSandbox, path)
+	}
+	return strings.TrimSuffix(baseURL, "/") + path
+}
+
+// DefaultURL returns the URL of path on Africa's Talking's own host for the environment
+func DefaultURL(host Host, isSandbox bool, path string) string {
+	if isSandbox {
+		return sandboxBaseURLs[host] + path
+	}
+	return liveBaseURLs[host] + path
+}
+
+// ResolveURL resolves path using resolver, falling back to DefaultURL when resolver is nil
+func ResolveURL(resolver Resolver, host Host, isSandbox bool, path string) string {
+	if resolver == nil {
+		return DefaultURL(host, isSandbox, path)
+	}
+	return resolver.URL(host, isSandbox, path)
+}
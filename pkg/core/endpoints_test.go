@@ -0,0 +1,27 @@
+package core
+
+import "testing"
+
+func TestResolveURL(t *testing.T) {
+	tests := []struct {
+		resolver  Resolver
+		host      Host
+		isSandbox bool
+		expected  string
+	}{
+		{nil, HostAPI, false, "https://api.africastalking.com/version1/messaging"},
+		{nil, HostContent, true, "https://api.sandbox.africastalking.com/version1/messaging"},
+		{&Endpoints{Voice: "http://127.0.0.1:8080/"}, HostVoice, false, "http://127.0.0.1:8080/version1/messaging"},
+		{&Endpoints{Voice: "http://127.0.0.1:8080"}, HostPayments, true, "https://payments.sandbox.africastalking.com/version1/messaging"},
+		{AllHosts("http://proxy.local"), HostContent, false, "http://proxy.local/version1/messaging"},
+		{ResolverFunc(func(host Host, isSandbox bool, path string) string {
+			return "https://gateway.example.com/" + string(host) + path
+		}), HostAPI, true, "https://gateway.example.com/api/version1/messaging"},
+	}
+	for _, test := range tests {
+		url := ResolveURL(test.resolver, test.host, test.isSandbox, "/version1/messaging")
+		if url != test.expected {
+			t.Fatalf("expected url='%s' got url='%s'", test.expected, url)
+		}
+	}
+}
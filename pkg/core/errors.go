@@ -0,0 +1,118 @@
+/*
+Package core provides the HTTP plumbing shared by every Africa's Talking service package
+*/
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when Africa's Talking API responds with an HTTP status of 400 or above
+type APIError struct {
+	StatusCode int    // HTTP status code returned by the API
+	Body       string // Raw response body
+	Message    string // Human readable error message parsed from the body
+	Endpoint   string // URL of the request that failed
+	RequestID  string // Request identifier returned in the response headers, if any
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("africastalking: %s returned %d: %s", e.Endpoint, e.StatusCode, message)
+}
+
+// IsAuthError reports whether the API rejected the request's credentials
+func (e *APIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsValidationError reports whether the API rejected the request's parameters
+func (e *APIError) IsValidationError() bool {
+	return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+}
+
+// IsRateLimited reports whether the API throttled the request
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsRetryable reports whether the request may succeed if sent again
+func (e *APIError) IsRetryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsAuthError reports whether err is an APIError caused by invalid credentials
+func IsAuthError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsAuthError()
+}
+
+// IsValidationError reports whether err is an APIError caused by invalid request parameters
+func IsValidationError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsValidationError()
+}
+
+// IsRateLimited reports whether err is an APIError caused by throttling
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsRateLimited()
+}
+
+// IsRetryable reports whether err is an APIError for a request that may succeed if sent again
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsRetryable()
+}
+
+// CheckResponse returns an *APIError if the response has an HTTP status of 400 or above
+func CheckResponse(response *http.Response) error {
+	if response.StatusCode < 400 {
+		return nil
+	}
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("africastalking: reading error response: %w", err)
+	}
+	body := string(bodyBytes)
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Body:       body,
+		Message:    parseErrorMessage(body),
+		RequestID:  response.Header.Get("X-Request-Id"),
+	}
+	if response.Request != nil && response.Request.URL != nil {
+		apiErr.Endpoint = response.Request.URL.String()
+	}
+	return apiErr
+}
+
+// parseErrorMessage extracts the error message from a JSON error body, falling back to the raw body
+func parseErrorMessage(body string) string {
+	res := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(body), &res); err == nil {
+		for _, key := range []string{"errorMessage", "message", "description", "error"} {
+			if message, ok := res[key].(string); ok && message != "" {
+				return message
+			}
+		}
+	}
+	return strings.TrimSpace(body)
+}
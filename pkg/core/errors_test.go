@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newResponse(statusCode int, body string) *http.Response {
+	endpoint, _ := url.Parse("https://api.sandbox.africastalking.com/version1/messaging")
+	header := make(http.Header)
+	header.Set("X-Request-Id", "req-1")
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     header,
+		Request:    &http.Request{URL: endpoint},
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	if err := CheckResponse(newResponse(http.StatusCreated, "")); err != nil {
+		t.Fatalf("expected no error for 201 got %v", err)
+	}
+
+	err := CheckResponse(newResponse(http.StatusUnauthorized, "The supplied authentication is invalid\n"))
+	wrapped := fmt.Errorf("sending sms: %w", err)
+
+	var apiErr *APIError
+	if !errors.As(wrapped, &apiErr) {
+		t.Fatalf("expected *APIError got %T", err)
+	}
+	if apiErr.Message != "The supplied authentication is invalid" {
+		t.Fatalf("expected message='The supplied authentication is invalid' got message='%s'", apiErr.Message)
+	}
+	if apiErr.RequestID != "req-1" {
+		t.Fatalf("expected requestId='req-1' got requestId='%s'", apiErr.RequestID)
+	}
+	if apiErr.Endpoint != "https://api.sandbox.africastalking.com/version1/messaging" {
+		t.Fatalf("unexpected endpoint='%s'", apiErr.Endpoint)
+	}
+	if !IsAuthError(wrapped) || IsRetryable(wrapped) {
+		t.Fatalf("expected auth error that is not retryable")
+	}
+}
+
+func TestCheckResponseClassification(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		message    string
+		validation bool
+		limited    bool
+		retryable  bool
+	}{
+		{http.StatusBadRequest, `{"errorMessage":"Invalid phone number"}`, "Invalid phone number", true, false, false},
+		{http.StatusTooManyRequests, "Too many requests", "Too many requests", false, true, true},
+		{http.StatusBadGateway, "", "", false, false, true},
+		{http.StatusNotImplemented, "", "", false, false, false},
+	}
+	for _, test := range tests {
+		err := CheckResponse(newResponse(test.statusCode, test.body))
+		if err.(*APIError).Message != test.message {
+			t.Fatalf("%d: expected message='%s' got message='%s'", test.statusCode, test.message, err.(*APIError).Message)
+		}
+		if IsValidationError(err) != test.validation || IsRateLimited(err) != test.limited || IsRetryable(err) != test.retryable {
+			t.Fatalf("%d: unexpected classification for %v", test.statusCode, err)
+		}
+	}
+	if IsRetryable(errors.New("boom")) {
+		t.Fatalf("expected non APIError to not be retryable")
+	}
+}
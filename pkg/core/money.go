@@ -0,0 +1,283 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinorUnits is the number of minor units in one unit of currency.
+// Africa's Talking reports amounts to 4 decimal places e.g "KES 0.8000"
+const MinorUnits = 10000
+
+// ErrCurrencyMismatch is returned when combining amounts of different currencies
+var ErrCurrencyMismatch = errors.New("africastalking: currency mismatch")
+
+// ErrOverflow is returned when an amount does not fit in Money, whose Minor must be within ±math.MaxInt64
+var ErrOverflow = errors.New("africastalking: amount out of range")
+
+/*
+Money is an exact amount of a currency stored as fixed-point integer minor units, see MinorUnits.
+
+Money is formatted as "KES 123.45" like amounts in Africa's Talking APIs and marshals to JSON in
+that format. The zero value has no currency and can be added to an amount of any currency
+*/
+type Money struct {
+	Currency string // Currency code e.g "KES"
+	Minor    int64  // Minor is the amount in ten-thousandths of a unit, e.g 123.45 is 1234500
+}
+
+// NewMoney creates an amount of whole units of currency e.g NewMoney("KES", 10) for KES 10.00,
+// failing with ErrOverflow if it does not fit in Money
+func NewMoney(currency string, units int64) (Money, error) {
+	minor, err := mulMinor(units, MinorUnits)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Currency: currency, Minor: minor}, nil
+}
+
+// MustNewMoney is like NewMoney but panics if the amount does not fit in Money, for use with constant amounts
+func MustNewMoney(currency string, units int64) Money {
+	m, err := NewMoney(currency, units)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// ParseMoney parses an amount formatted as "KES 123.45". The currency is optional, "0" parses as a zero amount
+func ParseMoney(s string) (Money, error) {
+	fields := strings.Fields(s)
+	m := Money{}
+	var amount string
+	switch len(fields) {
+	case 1:
+		amount = fields[0]
+	case 2:
+		m.Currency, amount = fields[0], fields[1]
+	default:
+		return Money{}, fmt.Errorf("africastalking: invalid amount %q", s)
+	}
+	minor, err := parseMinor(amount)
+	if errors.Is(err, ErrOverflow) {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+	if err != nil {
+		return Money{}, fmt.Errorf("africastalking: invalid amount %q: %w", s, err)
+	}
+	m.Minor = minor
+	return m, nil
+}
+
+// MustParseMoney is like ParseMoney but panics if s cannot be parsed, for use with constant amounts
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// parseMinor parses a decimal amount into minor units without going through floating point
+func parseMinor(amount string) (int64, error) {
+	negative := false
+	if rest, ok := strings.CutPrefix(amount, "-"); ok {
+		negative, amount = true, rest
+	}
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" {
+		return 0, errors.New("missing digits")
+	}
+	if len(fraction) > 4 {
+		return 0, errors.New("more than 4 decimal places")
+	}
+	for _, part := range []string{whole, fraction} {
+		if strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			return 0, errors.New("not a decimal number")
+		}
+	}
+	units := int64(0)
+	if whole != "" {
+		var err error
+		if units, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, ErrOverflow
+		}
+	}
+	minor, err := mulMinor(units, MinorUnits)
+	if err != nil {
+		return 0, err
+	}
+	if fraction != "" {
+		f, _ := strconv.ParseInt(fraction+strings.Repeat("0", 4-len(fraction)), 10, 64)
+		if minor, err = addMinor(minor, f); err != nil {
+			return 0, err
+		}
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// addMinor returns a + b, failing with ErrOverflow if the sum is not within ±math.MaxInt64
+func addMinor(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) || sum == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// mulMinor returns a * n, failing with ErrOverflow if the product is not within ±math.MaxInt64
+func mulMinor(a, n int64) (int64, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	product := a * n
+	if product/n != a || product == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return product, nil
+}
+
+// String formats the amount as "KES 123.45", showing up to 4 decimal places
+func (m Money) String() string {
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	fraction := strings.TrimRight(fmt.Sprintf("%04d", minor%MinorUnits), "0")
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	amount := fmt.Sprintf("%s%d.%s", sign, minor/MinorUnits, fraction)
+	if m.Currency == "" {
+		return amount
+	}
+	return m.Currency + " " + amount
+}
+
+// Float64 returns the amount as a float64, for display or APIs that require floating point
+func (m Money) Float64() float64 {
+	return float64(m.Minor) / MinorUnits
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Sign returns -1, 0 or +1 depending on whether the amount is negative, zero or positive
+func (m Money) Sign() int {
+	switch {
+	case m.Minor < 0:
+		return -1
+	case m.Minor > 0:
+		return 1
+	}
+	return 0
+}
+
+// currency returns the currency of combining m with o, allowing either to be the zero value
+func (m Money) currency(o Money) (string, error) {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency, nil
+	case m == Money{}:
+		return o.Currency, nil
+	case o == Money{}:
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+}
+
+// Add returns m + o, failing with ErrCurrencyMismatch if their currencies differ or ErrOverflow if the sum
+// does not fit in Money
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.currency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	minor, err := addMinor(m.Minor, o.Minor)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Currency: currency, Minor: minor}, nil
+}
+
+// Sub returns m - o, failing with ErrCurrencyMismatch if their currencies differ or ErrOverflow if the
+// difference does not fit in Money
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+// Mul returns the amount multiplied by n, failing with ErrOverflow if the product does not fit in Money
+func (m Money) Mul(n int64) (Money, error) {
+	minor, err := mulMinor(m.Minor, n)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Currency: m.Currency, Minor: minor}, nil
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{Currency: m.Currency, Minor: -m.Minor}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than o,
+// failing with ErrCurrencyMismatch if their currencies differ
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.currency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Minor < o.Minor:
+		return -1, nil
+	case m.Minor > o.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// MarshalText implements encoding.TextMarshaler, formatting the amount as "KES 123.45"
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see ParseMoney. Empty text is a zero amount
+func (m *Money) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) == 0 {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalJSON accepts amounts as "KES 123.45" strings or bare numbers, treating null as zero
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*m = Money{}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		var number json.Number
+		if err := json.Unmarshal(b, &number); err != nil {
+			return fmt.Errorf("africastalking: invalid amount %s", b)
+		}
+		text = number.String()
+	}
+	return m.UnmarshalText([]byte(text))
+}
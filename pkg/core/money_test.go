@@ -0,0 +1,131 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input  string
+		money  Money
+		output string
+	}{
+		{"KES 0.8000", Money{Currency: "KES", Minor: 8000}, "KES 0.80"},
+		{"KES 123.45", Money{Currency: "KES", Minor: 1234500}, "KES 123.45"},
+		{"UGX 100", Money{Currency: "UGX", Minor: 1000000}, "UGX 100.00"},
+		{"KES 0.8350", Money{Currency: "KES", Minor: 8350}, "KES 0.835"},
+		{"  NGN   -1.5 ", Money{Currency: "NGN", Minor: -15000}, "NGN -1.50"},
+		{"0", Money{}, "0.00"},
+	}
+	for _, test := range tests {
+		money, err := ParseMoney(test.input)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", test.input, err)
+		}
+		if money != test.money {
+			t.Fatalf("%q: expected %+v got %+v", test.input, test.money, money)
+		}
+		if money.String() != test.output {
+			t.Fatalf("%q: expected string='%s' got string='%s'", test.input, test.output, money.String())
+		}
+	}
+
+	for _, input := range []string{"", "KES", "KES ten", "KES 1.23456", "KES 1 2", "KES -", "KES 1e3", "KES 99999999999999999999", "KES 922337203685477.9999"} {
+		if _, err := ParseMoney(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	total := Money{}
+	for i := 0; i < 10; i++ {
+		var err error
+		if total, err = total.Add(MustParseMoney("KES 0.1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total != MustNewMoney("KES", 1) {
+		t.Fatalf("expected exactly KES 1.00 got %s", total)
+	}
+
+	diff, err := total.Sub(MustParseMoney("KES 1.25"))
+	if err != nil || diff.String() != "KES -0.25" || diff.Sign() != -1 {
+		t.Fatalf("unexpected difference %s, %v", diff, err)
+	}
+	if got, err := MustParseMoney("KES 0.80").Mul(3); err != nil || got.String() != "KES 2.40" {
+		t.Fatalf("expected KES 2.40 got %s, %v", got, err)
+	}
+
+	if cmp, err := MustNewMoney("KES", 2).Cmp(MustNewMoney("KES", 10)); err != nil || cmp != -1 {
+		t.Fatalf("expected KES 2 < KES 10 got cmp=%d, %v", cmp, err)
+	}
+	if _, err := MustNewMoney("KES", 1).Add(MustNewMoney("UGX", 1)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch got %v", err)
+	}
+	if _, err := MustNewMoney("KES", 1).Cmp(MustNewMoney("UGX", 1)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch got %v", err)
+	}
+}
+
+func TestMoneyOverflow(t *testing.T) {
+	largest := MustParseMoney("KES 922337203685477.5807")
+	if largest.Minor != math.MaxInt64 {
+		t.Fatalf("expected the largest amount to parse got %+v", largest)
+	}
+	if _, err := ParseMoney("KES 922337203685477.5808"); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow got %v", err)
+	}
+	if _, err := largest.Add(MustParseMoney("KES 0.0001")); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow got %v", err)
+	}
+	if _, err := largest.Neg().Sub(MustParseMoney("KES 0.0001")); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow got %v", err)
+	}
+	if _, err := largest.Mul(2); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow got %v", err)
+	}
+	if _, err := NewMoney("KES", math.MaxInt64/MinorUnits+1); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow got %v", err)
+	}
+	if cmp, err := largest.Cmp(largest.Neg()); err != nil || cmp != 1 {
+		t.Fatalf("expected the largest amount to compare greater than its negation got cmp=%d, %v", cmp, err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	value := struct {
+		Cost     Money `json:"cost"`
+		Discount Money `json:"discount"`
+		Number   Money `json:"number"`
+	}{}
+	if err := json.Unmarshal([]byte(`{"cost":"KES 0.8000","discount":null,"number":1.5}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	if value.Cost != MustParseMoney("KES 0.8") || !value.Discount.IsZero() || value.Number.Minor != 15000 {
+		t.Fatalf("unexpected values %+v", value)
+	}
+
+	b, err := json.Marshal(value.Cost)
+	if err != nil || string(b) != `"KES 0.80"` {
+		t.Fatalf("expected \"KES 0.80\" got %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`true`), &value.Cost); err == nil {
+		t.Fatalf("expected an error for a non-amount value")
+	}
+}
+
+func TestMoneyErrorsArePrefixed(t *testing.T) {
+	_, mismatch := MustNewMoney("KES", 1).Add(MustNewMoney("UGX", 1))
+	_, invalid := ParseMoney("KES ten")
+	_, overflow := ParseMoney("KES 922337203685477.5808")
+	for _, err := range []error{mismatch, invalid, overflow} {
+		if err == nil || !strings.HasPrefix(err.Error(), "africastalking: ") || strings.Count(err.Error(), "africastalking:") != 1 {
+			t.Fatalf("expected a single 'africastalking: ' prefix got %v", err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by a fail-fast RateLimiter when no request quota is available
+var ErrRateLimited = errors.New("africastalking: client rate limit exceeded")
+
+// bucket is a token bucket refilled at rate tokens per second up to burst tokens
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+/*
+RateLimiter is a token bucket rate limiter that keeps every goroutine sharing a client under the
+account's request quota.
+
+A global limit applies to every request and optional per-endpoint limits, keyed by Endpoint
+(e.g core.Endpoint(core.HostAPI, "/version1/messaging") for bulk SMS), apply on top of it. A RateLimiter is safe for concurrent use and may
+be shared by several service clients. The zero value has no global limit and is ready to use.
+*/
+type RateLimiter struct {
+	FailFast bool // FailFast returns ErrRateLimited instead of waiting when no quota is available
+
+	mu        sync.Mutex
+	global    *bucket
+	endpoints map[string]*bucket
+}
+
+// NewRateLimiter creates a RateLimiter allowing rate requests per second with bursts of up to burst requests.
+// A rate of zero or less leaves requests unlimited unless an endpoint limit is set
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	l := &RateLimiter{endpoints: make(map[string]*bucket)}
+	if rate > 0 {
+		l.global = newBucket(rate, burst)
+	}
+	return l
+}
+
+// SetEndpointLimit limits requests to endpoint, see Endpoint, to rate requests per second with bursts of up to burst requests
+func (l *RateLimiter) SetEndpointLimit(endpoint string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate <= 0 {
+		delete(l.endpoints, endpoint)
+		return
+	}
+	if l.endpoints == nil {
+		l.endpoints = make(map[string]*bucket)
+	}
+	l.endpoints[endpoint] = newBucket(rate, burst)
+}
+
+// refund returns the tokens reserved from buckets by a request that was not sent
+func refund(buckets []*bucket) {
+	for _, b := range buckets {
+		b.tokens = math.Min(b.burst, b.tokens+1)
+	}
+}
+
+// buckets returns the buckets that apply to endpoint
+func (l *RateLimiter) buckets(endpoint string) []*bucket {
+	buckets := []*bucket{}
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	if b, ok := l.endpoints[endpoint]; ok {
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// Wait blocks until a request to endpoint is allowed or ctx is done. A nil RateLimiter never blocks.
+// In fail-fast mode it returns ErrRateLimited immediately when no quota is available
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	buckets := l.buckets(endpoint)
+	var delay time.Duration
+	for _, b := range buckets {
+		if d := b.reserve(now); d > delay {
+			delay = d
+		}
+	}
+	if delay == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if l.FailFast || (hasDeadline && deadline.Before(now.Add(delay))) {
+		refund(buckets)
+		l.mu.Unlock()
+		if l.FailFast {
+			return ErrRateLimited
+		}
+		return context.DeadlineExceeded
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		refund(buckets)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
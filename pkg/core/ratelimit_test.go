@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBlocks(t *testing.T) {
+	limiter := NewRateLimiter(50, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), "/version1/messaging"); err != nil {
+			t.Fatalf("wait failed: %s", err.Error())
+		}
+	}
+	// The first request uses the burst and the next two wait 20ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("expected limiter to wait got elapsed=%s", elapsed)
+	}
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	// The zero value is usable, endpoint limits included
+	limiter := &RateLimiter{FailFast: true}
+	limiter.SetEndpointLimit("/version1/messaging", 1, 2)
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(context.Background(), "/version1/messaging"); err != nil {
+			t.Fatalf("expected burst of 2 to be allowed got %v", err)
+		}
+	}
+	if err := limiter.Wait(context.Background(), "/version1/messaging"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited got %v", err)
+	}
+	if err := limiter.Wait(context.Background(), "/version1/airtime/send"); err != nil {
+		t.Fatalf("expected other endpoints to be unlimited got %v", err)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.Wait(context.Background(), "/call")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "/call"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := limiter.Wait(ctx, "/call"); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled got %v", err)
+		}
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	wg.Wait()
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var limiter *RateLimiter
+	if err := limiter.Wait(context.Background(), "/call"); err != nil {
+		t.Fatalf("expected nil limiter to never block got %v", err)
+	}
+}
+
+func TestRateLimiterRefundIsCapped(t *testing.T) {
+	b := newBucket(1, 2)
+	refund([]*bucket{b})
+	if b.tokens != 2 {
+		t.Fatalf("expected refund to stop at burst got tokens=%v", b.tokens)
+	}
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header Africa's Talking uses to de-duplicate requests, making them safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how transient failures are retried
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts including the first, values below 2 disable retries
+	InitialBackoff       time.Duration // Delay before the first retry
+	MaxBackoff           time.Duration // Upper bound of the delay between attempts, including delays requested by Retry-After
+	Multiplier           float64       // Factor the delay grows by after every attempt, defaults to 2
+	Jitter               float64       // Fraction (0-1) of each delay that is randomised to spread out retries
+	RetryableStatusCodes []int         // HTTP status codes that are retried, only 429 is retried for requests that are not idempotent
+}
+
+// DefaultRetryPolicy returns the retry policy used by the root africastalking client
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// backoff returns the delay before the given retry (1 for the first retry)
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// retryableStatus reports whether the policy retries responses with statusCode
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header as either seconds or an HTTP date
+func retryAfter(response *http.Response) time.Duration {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// idempotent reports whether req can be repeated without side effects: reads, and writes carrying an Idempotency-Key header
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// retryableError reports whether a failed attempt may be retried. Requests that are not idempotent
+// are only retried when the failure happened before the request could reach Africa's Talking
+func retryableError(err error, idempotent bool) bool {
+	// *url.Error implements net.Error itself, classify the failure it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			// http.Client timeouts may fire after the request was sent
+			return idempotent
+		}
+		err = urlErr.Err
+	}
+	var (
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+/*
+Do sends req with client, retrying transient failures according to policy.
+Every attempt first waits for quota from limiter for endpoint, see Endpoint.
+
+Only idempotent requests, GET requests or writes carrying an Idempotency-Key header, are retried on
+every connection failure and retryable status code. Other requests, such as sending SMS or placing
+calls, may already have been acted on after an ambiguous failure, so they are only retried when the
+connection could not be established or on 429 Too Many Requests. Permanent failures such as invalid
+URLs, TLS errors and unknown hosts are never retried.
+
+A nil policy sends the request once and a nil limiter never waits.
+*/
+func Do(client *http.Client, policy *RetryPolicy, limiter *RateLimiter, req *http.Request, endpoint string) (*http.Response, error) {
+	attempts := 1
+	if policy != nil && policy.MaxAttempts > 1 {
+		attempts = policy.MaxAttempts
+	}
+	if attempts > 1 && req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+	safe := idempotent(req)
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		if err := limiter.Wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(attemptReq)
+		if attempt == attempts {
+			return resp, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || !retryableError(err, safe) {
+				return nil, err
+			}
+			delay = policy.backoff(attempt)
+		case policy.retryableStatus(resp.StatusCode) && (safe || resp.StatusCode == http.StatusTooManyRequests):
+			delay = policy.backoff(attempt)
+			if after := retryAfter(resp); after > delay {
+				delay = after
+			}
+			if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+				delay = policy.MaxBackoff
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.Jitter = 0
+	return policy
+}
+
+// flakyServer fails the first failures requests with statusCode and records every request body
+func flakyServer(t *testing.T, failures int32, statusCode int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "username=sandbox" {
+			t.Errorf("expected request body to be resent got body='%s'", body)
+		}
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+func newPost(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer([]byte("username=sandbox")))
+	if err != nil {
+		t.Fatalf("failed to create request: %s", err.Error())
+	}
+	return req
+}
+
+// newIdempotentPost creates a POST request carrying an Idempotency-Key header
+func newIdempotentPost(t *testing.T, url string) *http.Request {
+	req := newPost(t, url)
+	req.Header.Set(IdempotencyKeyHeader, "txn-1")
+	return req
+}
+
+// roundTripFunc adapts a function into an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// failingClient fails every request with err, counting the attempts in calls
+func failingClient(err error, calls *int32) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(calls, 1)
+		return nil, err
+	})}
+}
+
+func TestDoRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 2, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	resp, err := Do(server.Client(), testPolicy(), nil, newIdempotentPost(t, server.URL), "")
+	if err != nil {
+		t.Fatalf("request failed: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusCreated || calls != 3 {
+		t.Fatalf("expected success after 3 calls got status=%d calls=%d", resp.StatusCode, calls)
+	}
+}
+
+func TestDoGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 5, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	resp, err := Do(server.Client(), testPolicy(), nil, newIdempotentPost(t, server.URL), "")
+	if err != nil {
+		t.Fatalf("request failed: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 3 {
+		t.Fatalf("expected last failure after 3 calls got status=%d calls=%d", resp.StatusCode, calls)
+	}
+}
+
+func TestDoDoesNotRetryAmbiguousFailures(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 1, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	resp, _ := Do(server.Client(), testPolicy(), nil, newPost(t, server.URL), "")
+	if resp.StatusCode != http.StatusBadGateway || calls != 1 {
+		t.Fatalf("expected a single attempt got status=%d calls=%d", resp.StatusCode, calls)
+	}
+
+	calls = 0
+	if _, err := Do(failingClient(io.ErrUnexpectedEOF, &calls), testPolicy(), nil, newPost(t, "http://example.com"), ""); err == nil || calls != 1 {
+		t.Fatalf("expected a connection failure after sending not to be retried got calls=%d", calls)
+	}
+
+	calls = 0
+	if _, err := Do(failingClient(io.ErrUnexpectedEOF, &calls), testPolicy(), nil, newIdempotentPost(t, "http://example.com"), ""); err == nil || calls != 3 {
+		t.Fatalf("expected an idempotent request to be retried got calls=%d", calls)
+	}
+}
+
+func TestDoRetriesFailuresBeforeSending(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 1, http.StatusTooManyRequests, &calls)
+	defer server.Close()
+
+	resp, _ := Do(server.Client(), testPolicy(), nil, newPost(t, server.URL), "")
+	if resp.StatusCode != http.StatusCreated || calls != 2 {
+		t.Fatalf("expected a rate limited request to be retried got status=%d calls=%d", resp.StatusCode, calls)
+	}
+
+	calls = 0
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	if _, err := Do(failingClient(dialErr, &calls), testPolicy(), nil, newPost(t, "http://example.com"), ""); err == nil || calls != 3 {
+		t.Fatalf("expected dial failures to be retried got calls=%d", calls)
+	}
+}
+
+func TestDoDoesNotRetryPermanentErrors(t *testing.T) {
+	permanent := []error{
+		x509.UnknownAuthorityError{},
+		&net.DNSError{Err: "no such host", Name: "api.example", IsNotFound: true},
+		errors.New("unsupported protocol scheme"),
+	}
+	for _, err := range permanent {
+		var calls int32
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		if _, doErr := Do(failingClient(err, &calls), testPolicy(), nil, req, ""); doErr == nil || calls != 1 {
+			t.Fatalf("%v: expected a single attempt got calls=%d", err, calls)
+		}
+	}
+}
+
+func TestDoCapsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	policy := testPolicy()
+	policy.MaxBackoff = 10 * time.Millisecond
+	resp, err := Do(server.Client(), policy, nil, newPost(t, server.URL), "")
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected Retry-After to be capped at MaxBackoff got %v", err)
+	}
+}
+
+func TestDoRespectsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := newPost(t, server.URL).WithContext(ctx)
+
+	_, err := Do(server.Client(), testPolicy(), nil, req, "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected to wait for Retry-After beyond the deadline got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected calls=1 got calls=%d", calls)
+	}
+}
+
+func TestDoWithoutPolicy(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 1, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	resp, _ := Do(server.Client(), nil, nil, newIdempotentPost(t, server.URL), "")
+	if resp.StatusCode != http.StatusBadGateway || calls != 1 {
+		t.Fatalf("expected a single attempt got status=%d calls=%d", resp.StatusCode, calls)
+	}
+}
+
+func TestDoLimitsByEndpoint(t *testing.T) {
+	var calls int32
+	server := flakyServer(t, 0, http.StatusCreated, &calls)
+	defer server.Close()
+
+	limiter := NewRateLimiter(0, 0)
+	limiter.FailFast = true
+	bulk := Endpoint(HostAPI, "/version1/messaging")
+	limiter.SetEndpointLimit(bulk, 0.001, 1)
+
+	if _, err := Do(server.Client(), nil, limiter, newPost(t, server.URL+"/proxy/version1/messaging"), bulk); err != nil {
+		t.Fatalf("expected the first request to be allowed got %v", err)
+	}
+	if _, err := Do(server.Client(), nil, limiter, newPost(t, server.URL+"/proxy/version1/messaging"), bulk); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected the endpoint limit to apply regardless of the URL got %v", err)
+	}
+	if _, err := Do(server.Client(), nil, limiter, newPost(t, server.URL+"/version1/messaging"), Endpoint(HostContent, "/version1/messaging")); err != nil {
+		t.Fatalf("expected premium SMS not to share the bulk SMS limit got %v", err)
+	}
+}
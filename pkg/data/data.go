@@ -7,12 +7,18 @@ package data
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"io"
-	"log"
+	"fmt"
 	"net/http"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
 )
 
+const sendPath = "/mobile/data/request"
+
+// Recipient represents the target user to receive mobile data
 type Recipient struct {
 	PhoneNumber   string      `json:"phone_number"`    // PhoneNumber represents the phone number that will be topped up in international format (e.g +234811222333).
 	Quantity      int         `json:"quantity"`        // Quantity refers The amount of data
@@ -24,18 +30,19 @@ type Recipient struct {
 
 // Request represents the request body send mobile data request
 type Request struct {
-	Username    string      `json:"username"`    //Username represents the Africa’s Talking application username.
-	ProductName string      `json:"productName"` //ProductName refers to the application product name.
-	Recipients  []Recipient `json:"recipients"`  // Recipients represents a list of Recipients
+	Username       string      `json:"username"`    //Username represents the Africa’s Talking application username, defaults to Client.Username
+	ProductName    string      `json:"productName"` //ProductName refers to the application product name.
+	Recipients     []Recipient `json:"recipients"`  // Recipients represents a list of Recipients
+	IdempotencyKey string      `json:"-"`           // IdempotencyKey de-duplicates the request and allows it to be retried safely (optional)
 }
 
 // Entry is an individual data transaction result
 type Entry struct {
-	PhoneNumber   string `json:"phoneNumber"`   //The phone number for this transaction.
-	Provider      string `json:"provider"`      // This is the name of the service provider.
-	Status        string `json:"status"`        // The status of the request associated to this phone number. This could be Queued
-	TransactionId string `json:"transactionId"` // A unique id for the request associated to this transaction.
-	Value         string `json:"value"`         // The value of data sent. The format of this string is: (3-digit Currency Code)(space)(Decimal Value) e.g KES 200.00
+	PhoneNumber   string     `json:"phoneNumber"`   //The phone number for this transaction.
+	Provider      string     `json:"provider"`      // This is the name of the service provider.
+	Status        string     `json:"status"`        // The status of the request associated to this phone number. This could be Queued
+	TransactionId string     `json:"transactionId"` // A unique id for the request associated to this transaction.
+	Value         core.Money `json:"value"`         // The value of data sent e.g KES 200.00
 }
 
 // Response represents the response from Africa's Talking API
@@ -45,13 +52,17 @@ type Response struct {
 
 /*
 Client represents the http client for communicating with Africa's
-Talking Api
+Talking Api. A Client is safe for concurrent use by multiple goroutines
 */
 type Client struct {
-	ApiKey    string       //Api Key provided by Africa's Talking
-	Username  string       // Your Africa's Talking application username
-	IsSandbox bool         // Is Sandbox specifies whether to use sandbox or live environment
-	Client    *http.Client // HTTP client for making requests to Africa's Talking API
+	ApiKey    string            //Api Key provided by Africa's Talking
+	Username  string            // Your Africa's Talking application username
+	IsSandbox bool              // Is Sandbox specifies whether to use sandbox or live environment
+	Client    *http.Client      // HTTP client for making requests to Africa's Talking API. Defaults to core.DefaultHTTPClient
+	Endpoints core.Resolver     // Endpoints resolves API URLs, defaults to Africa's Talking's hosts for the environment
+	Retry     *core.RetryPolicy // Retry controls how transient failures are retried, nil disables retries
+	Limiter   *core.RateLimiter // Limiter throttles requests client-side, nil disables rate limiting
+	Region    string            // Region normalises phone numbers to E.164 before sending, reading local numbers as numbers of this ISO country code e.g "KE". Empty sends numbers as given
 }
 
 // setHeaders configures required headers for the HTTP request to Africa's Talking API
@@ -61,40 +72,56 @@ func setHeaders(request *http.Request, apiKey string) {
 	request.Header.Set("Content-Type", "application/json")
 }
 
+// Send sends mobile data to the specified recipient(s)
 func (c *Client) Send(request *Request) (Response, error) {
-	c.Client = &http.Client{}
-	url := "https://payments.africastalking.com/mobile/data/request"
-
-	//Marshal turns the request struct into a []byte to be fed into the http request
-	b, _ := json.Marshal(request)
-
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(b))
+	return c.SendWithContext(context.Background(), request)
+}
 
-	setHeaders(req, c.ApiKey)
+// SendWithContext is like Send but aborts the request when ctx is cancelled or its deadline expires
+func (c *Client) SendWithContext(ctx context.Context, request *Request) (Response, error) {
+	url := core.ResolveURL(c.Endpoints, core.HostPayments, c.IsSandbox, sendPath)
 
-	response, err := c.Client.Do(req)
+	recipients, err := phonenumber.NormalizeEach(request.Recipients, c.Region, func(r *Recipient) *string { return &r.PhoneNumber })
+	if err != nil {
+		return Response{}, err
+	}
+	normalized := *request
+	normalized.Recipients = recipients
+	if normalized.Username == "" {
+		normalized.Username = c.Username
+	}
+	request = &normalized
 
+	//Marshal turns the request struct into a []byte to be fed into the http request
+	b, err := json.Marshal(request)
 	if err != nil {
-		log.Fatalf("Error making api request to Africa's Talking API %v", err)
+		return Response{}, fmt.Errorf("encoding mobile data request: %w", err)
 	}
 
-	defer response.Body.Close()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(b))
+	if err != nil {
+		return Response{}, err
+	}
 
-	responseBody, err := io.ReadAll(response.Body)
+	setHeaders(req, c.ApiKey)
+	if request.IdempotencyKey != "" {
+		req.Header.Set(core.IdempotencyKeyHeader, request.IdempotencyKey)
+	}
 
+	response, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostPayments, sendPath))
 	if err != nil {
-		log.Fatalf("Error reading response body into []byte : %v", err)
+		return Response{}, fmt.Errorf("making api request to Africa's Talking API: %w", err)
 	}
+	defer response.Body.Close()
 
-	var dataResponse Response
-
-	err = json.Unmarshal(responseBody, &dataResponse)
+	if err := core.CheckResponse(response); err != nil {
+		return Response{}, err
+	}
 
-	if err != nil {
-		log.Fatalf("Error unmarshalling json : %v", err)
+	dataResponse := Response{}
+	if err := core.DecodeJSON(response, &dataResponse); err != nil {
+		return Response{}, err
 	}
 
-	return Response{
-		Entries: dataResponse.Entries,
-	}, nil
+	return dataResponse, nil
 }
@@ -1,8 +1,14 @@
 package data
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
 )
 
 func TestSendData(t *testing.T) {
@@ -30,3 +36,44 @@ func TestSendData(t *testing.T) {
 		t.Fatalf("expected status = 'success' got status = '%s'", status)
 	}
 }
+
+// roundTripFunc adapts a function into an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSendDataErrors(t *testing.T) {
+	var requestedURL string
+	client := &Client{
+		ApiKey:    "api-key",
+		Username:  "sandbox",
+		IsSandbox: true,
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requestedURL = req.URL.String()
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(strings.NewReader("Bad Gateway")),
+				Header:     make(http.Header),
+				Request:    req,
+			}, nil
+		})},
+	}
+
+	_, err := client.Send(&Request{Username: "sandbox", ProductName: "barkenew"})
+	if !core.IsRetryable(err) {
+		t.Fatalf("expected retryable APIError got %v", err)
+	}
+	if requestedURL != "https://payments.sandbox.africastalking.com/mobile/data/request" {
+		t.Fatalf("expected sandbox url got url='%s'", requestedURL)
+	}
+
+	networkErr := errors.New("connection reset")
+	client.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, networkErr
+	})}
+	if _, err := client.Send(&Request{}); !errors.Is(err, networkErr) {
+		t.Fatalf("expected wrapped network error got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package phonenumber
+
+import (
+	"strings"
+	"sync"
+)
+
+// Network operators found in the default prefix table
+const (
+	Safaricom         = "Safaricom"
+	Airtel            = "Airtel"
+	Telkom            = "Telkom"
+	Equitel           = "Equitel"
+	MTN               = "MTN"
+	UTL               = "UTL"
+	Vodacom           = "Vodacom"
+	Tigo              = "Tigo"
+	Halotel           = "Halotel"
+	TTCL              = "TTCL"
+	Zantel            = "Zantel"
+	Glo               = "Glo"
+	NineMobile        = "9mobile"
+	TNM               = "TNM"
+	Zamtel            = "Zamtel"
+	CellC             = "Cell C"
+	EthioTelecom      = "Ethio Telecom"
+	SafaricomEthiopia = "Safaricom Ethiopia"
+	Telecel           = "Telecel"
+	AirtelTigo        = "AirtelTigo"
+	Orange            = "Orange"
+	Moov              = "Moov"
+	Free              = "Free"
+	Expresso          = "Expresso"
+	Nexttel           = "Nexttel"
+)
+
+// defaultPrefixes assigns the mobile number ranges of each operator, written as E.164 prefixes.
+// Numbers ported between networks keep their original prefix, so the operator is only a likely match
+var defaultPrefixes = []struct {
+	operator string
+	prefixes []string
+}{
+	// Kenya
+	{Safaricom, []string{"+25470", "+25471", "+25472", "+254740", "+254741", "+254742", "+254743", "+254745", "+254746", "+254748", "+254757", "+254758", "+254759", "+254768", "+254769", "+25479", "+254110", "+254111", "+254112", "+254113", "+254114", "+254115"}},
+	{Airtel, []string{"+25473", "+254750", "+254751", "+254752", "+254753", "+254754", "+254755", "+254756", "+254762", "+25478", "+254100", "+254101", "+254102"}},
+	{Telkom, []string{"+25477"}},
+	{Equitel, []string{"+254763", "+254764", "+254765", "+254766"}},
+	// Uganda
+	{MTN, []string{"+25676", "+25677", "+25678"}},
+	{Airtel, []string{"+25670", "+25674", "+25675"}},
+	{UTL, []string{"+25671"}},
+	// Tanzania
+	{Vodacom, []string{"+25574", "+25575", "+25576"}},
+	{Airtel, []string{"+25568", "+25569", "+25578"}},
+	{Tigo, []string{"+25565", "+25567", "+25571"}},
+	{Halotel, []string{"+25561", "+25562"}},
+	{TTCL, []string{"+25573"}},
+	{Zantel, []string{"+25577"}},
+	// Rwanda
+	{MTN, []string{"+25078", "+25079"}},
+	{Airtel, []string{"+25072", "+25073"}},
+	// Nigeria
+	{MTN, []string{"+234703", "+234706", "+234803", "+234806", "+234810", "+234813", "+234814", "+234816", "+234903", "+234906", "+234913", "+234916"}},
+	{Airtel, []string{"+234701", "+234708", "+234802", "+234808", "+234812", "+234901", "+234902", "+234904", "+234907", "+234912"}},
+	{Glo, []string{"+234705", "+234805", "+234807", "+234811", "+234815", "+234905", "+234915"}},
+	{NineMobile, []string{"+234809", "+234817", "+234818", "+234908", "+234909"}},
+	// Malawi
+	{Airtel, []string{"+26599"}},
+	{TNM, []string{"+26588"}},
+	// Zambia
+	{MTN, []string{"+26096", "+26076"}},
+	{Airtel, []string{"+26097", "+26077"}},
+	{Zamtel, []string{"+26095", "+26075"}},
+	// South Africa
+	{Vodacom, []string{"+2772", "+2776", "+2779", "+2782"}},
+	{MTN, []string{"+2773", "+2778", "+2783"}},
+	{CellC, []string{"+2774", "+2784"}},
+	{Telkom, []string{"+2781"}},
+	// Ethiopia
+	{EthioTelecom, []string{"+2519"}},
+	{SafaricomEthiopia, []string{"+2517"}},
+	// Ghana
+	{MTN, []string{"+23324", "+23325", "+23353", "+23354", "+23355", "+23359"}},
+	{Telecel, []string{"+23320", "+23350"}},
+	{AirtelTigo, []string{"+23326", "+23327", "+23356", "+23357"}},
+	// Ivory Coast
+	{Orange, []string{"+22507"}},
+	{MTN, []string{"+22505"}},
+	{Moov, []string{"+22501"}},
+	// Senegal
+	{Orange, []string{"+22177", "+22178"}},
+	{Free, []string{"+22176"}},
+	{Expresso, []string{"+22170"}},
+	// Cameroon
+	{MTN, []string{"+23767", "+237650", "+237651", "+237652", "+237653", "+237654", "+23768"}},
+	{Orange, []string{"+23769", "+237655", "+237656", "+237657", "+237658", "+237659"}},
+	{Nexttel, []string{"+23766"}},
+}
+
+// Carrier is the result of looking up a phone number in a PrefixTable
+type Carrier struct {
+	Number   PhoneNumber // Number that was looked up
+	Country  Country     // Country the number belongs to, including its ISO code and currency
+	Operator string      // Operator is the likely network operator e.g Safaricom, empty if the prefix is unknown
+}
+
+/*
+PrefixTable maps E.164 number prefixes to network operators, matching the longest prefix.
+
+Prefixes can be added or removed at runtime as operators are assigned new ranges.
+A PrefixTable is safe for concurrent use by multiple goroutines
+*/
+type PrefixTable struct {
+	mu        sync.RWMutex
+	operators map[string]string // operators by prefix digits, without the "+"
+}
+
+// NewPrefixTable creates an empty PrefixTable
+func NewPrefixTable() *PrefixTable {
+	return &PrefixTable{operators: map[string]string{}}
+}
+
+// newDefaultPrefixTable creates a PrefixTable holding defaultPrefixes
+func newDefaultPrefixTable() *PrefixTable {
+	t := NewPrefixTable()
+	for _, entry := range defaultPrefixes {
+		for _, prefix := range entry.prefixes {
+			t.Set(prefix, entry.operator)
+		}
+	}
+	return t
+}
+
+// DefaultPrefixes is the PrefixTable used by Lookup, holding the known ranges of every supported market
+var DefaultPrefixes = newDefaultPrefixTable()
+
+// Set assigns the numbers starting with prefix e.g "+25470" to operator, replacing any previous assignment
+func (t *PrefixTable) Set(prefix, operator string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.operators[strings.TrimPrefix(prefix, "+")] = operator
+}
+
+// Delete removes the assignment of prefix, numbers starting with it fall back to shorter prefixes
+func (t *PrefixTable) Delete(prefix string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.operators, strings.TrimPrefix(prefix, "+"))
+}
+
+// Operator returns the operator of the longest prefix matching number, or an empty string if none matches
+func (t *PrefixTable) Operator(number PhoneNumber) string {
+	digits := strings.TrimPrefix(number.E164(), "+")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for i := len(digits); i > 0; i-- {
+		if operator, ok := t.operators[digits[:i]]; ok {
+			return operator
+		}
+	}
+	return ""
+}
+
+// Lookup parses number, see Parse, and reports its country and likely operator
+func (t *PrefixTable) Lookup(number, defaultRegion string) (Carrier, error) {
+	p, err := Parse(number, defaultRegion)
+	if err != nil {
+		return Carrier{}, err
+	}
+	return Carrier{Number: p, Country: p.Country, Operator: t.Operator(p)}, nil
+}
+
+// Lookup reports the country and likely operator of number using DefaultPrefixes
+func Lookup(number, defaultRegion string) (Carrier, error) {
+	return DefaultPrefixes.Lookup(number, defaultRegion)
+}
@@ -0,0 +1,56 @@
+package phonenumber
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		number   string
+		iso      string
+		currency string
+		operator string
+	}{
+		{"+254712345678", "KE", "KES", Safaricom},
+		{"+254733123456", "KE", "KES", Airtel},
+		{"+254764123456", "KE", "KES", Equitel},
+		{"+254110123456", "KE", "KES", Safaricom},
+		{"+256772123456", "UG", "UGX", MTN},
+		{"+2348021234567", "NG", "NGN", Airtel},
+		{"+27821234567", "ZA", "ZAR", Vodacom},
+		{"+2250707123456", "CI", "XOF", Orange},
+		{"+237677123456", "CM", "XAF", MTN},
+		{"+265881234567", "MW", "MWK", TNM},
+		{"+265771234567", "MW", "MWK", ""},
+	}
+	for _, test := range tests {
+		carrier, err := Lookup(test.number, "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.number, err)
+		}
+		if carrier.Country.ISO != test.iso || carrier.Country.Currency != test.currency || carrier.Operator != test.operator {
+			t.Fatalf("%s: expected %s/%s/%q got %s/%s/%q", test.number, test.iso, test.currency, test.operator,
+				carrier.Country.ISO, carrier.Country.Currency, carrier.Operator)
+		}
+	}
+}
+
+func TestPrefixTableUpdates(t *testing.T) {
+	table := NewPrefixTable()
+	table.Set("+25471", Safaricom)
+	table.Set("+254712", Airtel)
+
+	carrier, err := table.Lookup("0712345678", "KE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if carrier.Operator != Airtel {
+		t.Fatalf("expected longest prefix to win got operator='%s'", carrier.Operator)
+	}
+
+	table.Delete("254712")
+	if carrier, _ := table.Lookup("0712345678", "KE"); carrier.Operator != Safaricom {
+		t.Fatalf("expected fallback to shorter prefix got operator='%s'", carrier.Operator)
+	}
+	if carrier, _ := table.Lookup("0722345678", "KE"); carrier.Operator != "" {
+		t.Fatalf("expected unknown operator got operator='%s'", carrier.Operator)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Package phonenumber parses phone numbers from the markets served by Africa's Talking and normalises them to E.164
+
+	number, err := phonenumber.Parse("0712 345 678", "KE")
+	fmt.Println(number.E164()) // +254712345678
+*/
+package phonenumber
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Country describes the numbering plan of a country served by Africa's Talking
+type Country struct {
+	Name           string   // Name of the country e.g "Kenya"
+	ISO            string   // ISO 3166-1 alpha-2 code e.g "KE"
+	CallingCode    string   // International calling code without the "+" e.g "254"
+	Currency       string   // Currency code used for airtime and payments e.g "KES", matching the airtime package constants
+	TrunkPrefix    string   // TrunkPrefix dialled before national numbers e.g "0", empty if numbers have none
+	Length         int      // Length of the national number, excluding the trunk prefix
+	MobilePrefixes []string // MobilePrefixes lists the leading digits of valid mobile national numbers
+}
+
+// countries lists the numbering plans of the supported markets
+var countries = []Country{
+	{Name: "Kenya", ISO: "KE", CallingCode: "254", Currency: "KES", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"7", "1"}},
+	{Name: "Uganda", ISO: "UG", CallingCode: "256", Currency: "UGX", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"7"}},
+	{Name: "Tanzania", ISO: "TZ", CallingCode: "255", Currency: "TZS", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"6", "7"}},
+	{Name: "Rwanda", ISO: "RW", CallingCode: "250", Currency: "RWF", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"7"}},
+	{Name: "Nigeria", ISO: "NG", CallingCode: "234", Currency: "NGN", TrunkPrefix: "0", Length: 10, MobilePrefixes: []string{"70", "80", "81", "90", "91"}},
+	{Name: "Malawi", ISO: "MW", CallingCode: "265", Currency: "MWK", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"77", "88", "98", "99"}},
+	{Name: "Zambia", ISO: "ZM", CallingCode: "260", Currency: "ZMW", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"7", "9"}},
+	{Name: "South Africa", ISO: "ZA", CallingCode: "27", Currency: "ZAR", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"6", "7", "8"}},
+	{Name: "Ethiopia", ISO: "ET", CallingCode: "251", Currency: "ETB", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"7", "9"}},
+	{Name: "Ghana", ISO: "GH", CallingCode: "233", Currency: "GHS", TrunkPrefix: "0", Length: 9, MobilePrefixes: []string{"2", "5"}},
+	{Name: "Ivory Coast", ISO: "CI", CallingCode: "225", Currency: "XOF", Length: 10, MobilePrefixes: []string{"01", "05", "07"}},
+	{Name: "Senegal", ISO: "SN", CallingCode: "221", Currency: "XOF", Length: 9, MobilePrefixes: []string{"7"}},
+	{Name: "Cameroon", ISO: "CM", CallingCode: "237", Currency: "XAF", Length: 9, MobilePrefixes: []string{"6"}},
+}
+
+// Countries returns the numbering plans of every supported market
+func Countries() []Country {
+	return append([]Country{}, countries...)
+}
+
+// CountryByISO returns the numbering plan of the country with the ISO 3166-1 alpha-2 code iso
+func CountryByISO(iso string) (Country, bool) {
+	for _, country := range countries {
+		if strings.EqualFold(country.ISO, iso) {
+			return country, true
+		}
+	}
+	return Country{}, false
+}
+
+// ParseError is returned when a phone number cannot be parsed
+type ParseError struct {
+	Number string // Number as given to Parse
+	Reason string // Reason the number was rejected
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("phonenumber: invalid phone number %q: %s", e.Number, e.Reason)
+}
+
+// PhoneNumber is a parsed phone number
+type PhoneNumber struct {
+	Country        Country // Country the number belongs to
+	NationalNumber string  // NationalNumber without the trunk prefix e.g "712345678"
+}
+
+// E164 formats the number in E.164 e.g "+254712345678"
+func (p PhoneNumber) E164() string {
+	return "+" + p.Country.CallingCode + p.NationalNumber
+}
+
+// String implements fmt.Stringer, returning the number in E.164
+func (p PhoneNumber) String() string {
+	return p.E164()
+}
+
+// digits strips the separators people type into phone numbers, returning false if other characters remain
+func digits(number string) (string, bool) {
+	b := strings.Builder{}
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '\u00a0':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// national validates the national number of country
+func national(country Country, nationalNumber string) (PhoneNumber, bool) {
+	if len(nationalNumber) != country.Length {
+		return PhoneNumber{}, false
+	}
+	for _, prefix := range country.MobilePrefixes {
+		if strings.HasPrefix(nationalNumber, prefix) {
+			return PhoneNumber{Country: country, NationalNumber: nationalNumber}, true
+		}
+	}
+	return PhoneNumber{}, false
+}
+
+// international parses digits starting with a calling code
+func international(number string) (PhoneNumber, bool) {
+	for _, country := range countries {
+		if rest, ok := strings.CutPrefix(number, country.CallingCode); ok {
+			if p, ok := national(country, rest); ok {
+				return p, true
+			}
+		}
+	}
+	return PhoneNumber{}, false
+}
+
+/*
+Parse parses a phone number written in local or international format.
+
+International numbers may start with "+", "00" or the bare calling code e.g "+254712345678",
+"00254712345678" or "254712345678". Local numbers e.g "0712 345 678" or "712345678" are parsed as
+belonging to defaultRegion, an ISO 3166-1 alpha-2 code such as "KE". Pass an empty defaultRegion
+to accept international numbers only.
+*/
+func Parse(number, defaultRegion string) (PhoneNumber, error) {
+	stripped, ok := digits(strings.TrimSpace(number))
+	if !ok {
+		return PhoneNumber{}, &ParseError{Number: number, Reason: "contains invalid characters"}
+	}
+	if stripped == "" {
+		return PhoneNumber{}, &ParseError{Number: number, Reason: "empty"}
+	}
+
+	if rest, ok := strings.CutPrefix(stripped, "+"); ok {
+		if p, ok := international(rest); ok {
+			return p, nil
+		}
+		return PhoneNumber{}, &ParseError{Number: number, Reason: "not a valid number for a supported country"}
+	}
+	if rest, ok := strings.CutPrefix(stripped, "00"); ok {
+		if p, ok := international(rest); ok {
+			return p, nil
+		}
+	}
+
+	var region Country
+	if defaultRegion != "" {
+		if region, ok = CountryByISO(defaultRegion); !ok {
+			return PhoneNumber{}, &ParseError{Number: number, Reason: fmt.Sprintf("unsupported region %q", defaultRegion)}
+		}
+		if region.TrunkPrefix != "" {
+			if rest, ok := strings.CutPrefix(stripped, region.TrunkPrefix); ok {
+				if p, ok := national(region, rest); ok {
+					return p, nil
+				}
+			}
+		}
+		if p, ok := national(region, stripped); ok {
+			return p, nil
+		}
+	}
+	if p, ok := international(stripped); ok {
+		return p, nil
+	}
+	if defaultRegion == "" {
+		return PhoneNumber{}, &ParseError{Number: number, Reason: "local number without a default region"}
+	}
+	return PhoneNumber{}, &ParseError{Number: number, Reason: fmt.Sprintf("not a valid %s number", region.Name)}
+}
+
+// Normalize parses number and formats it in E.164, see Parse
+func Normalize(number, defaultRegion string) (string, error) {
+	p, err := Parse(number, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return p.E164(), nil
+}
+
+// NormalizeAll normalises every number to E.164, returning a new slice. An empty defaultRegion returns
+// the numbers unchanged, so that clients without a Region send numbers as given.
+// The error joins a *ParseError for every invalid number
+func NormalizeAll(numbers []string, defaultRegion string) ([]string, error) {
+	normalized := make([]string, len(numbers))
+	if defaultRegion == "" {
+		copy(normalized, numbers)
+		return normalized, nil
+	}
+	errs := []error{}
+	for i, number := range numbers {
+		e164, err := Normalize(number, defaultRegion)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		normalized[i] = e164
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return normalized, nil
+}
+
+// NormalizeEach returns a copy of items with the phone number that field points to normalised in each item,
+// see NormalizeAll
+func NormalizeEach[T any](items []T, defaultRegion string, field func(item *T) *string) ([]T, error) {
+	numbers := make([]string, len(items))
+	for i := range items {
+		numbers[i] = *field(&items[i])
+	}
+	numbers, err := NormalizeAll(numbers, defaultRegion)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make([]T, len(items))
+	copy(normalized, items)
+	for i := range normalized {
+		*field(&normalized[i]) = numbers[i]
+	}
+	return normalized, nil
+}
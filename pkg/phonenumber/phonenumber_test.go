@@ -0,0 +1,108 @@
+package phonenumber
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		number string
+		region string
+		e164   string
+	}{
+		{"0712 345 678", "KE", "+254712345678"},
+		{"712345678", "KE", "+254712345678"},
+		{"254712345678", "KE", "+254712345678"},
+		{"+254 712-345-678", "", "+254712345678"},
+		{"00254712345678", "", "+254712345678"},
+		{"254712345678", "", "+254712345678"},
+		{"0110 123456", "ke", "+254110123456"},
+		{"0772 123456", "UG", "+256772123456"},
+		{"0803 123 4567", "NG", "+2348031234567"},
+		{"+234 803 123 4567", "KE", "+2348031234567"},
+		{"082 123 4567", "ZA", "+27821234567"},
+		{"07 07 12 34 56", "CI", "+2250707123456"},
+		{"77 123 45 67", "SN", "+221771234567"},
+	}
+	for _, test := range tests {
+		e164, err := Normalize(test.number, test.region)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", test.number, err)
+		}
+		if e164 != test.e164 {
+			t.Fatalf("%q: expected e164='%s' got e164='%s'", test.number, test.e164, e164)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		number string
+		region string
+	}{
+		{"", "KE"},
+		{"0712 345 67", "KE"},
+		{"0212 345 678", "KE"},
+		{"0712345678", ""},
+		{"+1 202 555 0100", "KE"},
+		{"0712-abc-678", "KE"},
+		{"0712345678", "US"},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.number, test.region)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%q: expected *ParseError got %v", test.number, err)
+		}
+		if parseErr.Number != test.number {
+			t.Fatalf("expected number='%s' got number='%s'", test.number, parseErr.Number)
+		}
+	}
+}
+
+func TestParseCountry(t *testing.T) {
+	number, err := Parse("0772 123456", "UG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number.Country.ISO != "UG" || number.NationalNumber != "772123456" {
+		t.Fatalf("unexpected number %+v", number)
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	numbers, err := NormalizeAll([]string{"0712345678", "+256772123456"}, "KE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers[0] != "+254712345678" || numbers[1] != "+256772123456" {
+		t.Fatalf("unexpected numbers %v", numbers)
+	}
+
+	_, err = NormalizeAll([]string{"0712", "0712345678", "abc"}, "KE")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Number != "0712" {
+		t.Fatalf("expected the first invalid number to be reported got %v", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("expected an error for every invalid number got %v", err)
+	}
+}
+
+func TestNormalizeEach(t *testing.T) {
+	type recipient struct {
+		PhoneNumber string
+		Amount      string
+	}
+	recipients := []recipient{{"0712 345 678", "KES 10"}}
+	normalized, err := NormalizeEach(recipients, "KE", func(r *recipient) *string { return &r.PhoneNumber })
+	if err != nil || normalized[0] != (recipient{"+254712345678", "KES 10"}) || recipients[0].PhoneNumber != "0712 345 678" {
+		t.Fatalf("expected a normalised copy got %+v err=%v", normalized, err)
+	}
+
+	numbers, err := NormalizeAll([]string{"0712 345 678"}, "")
+	if err != nil || numbers[0] != "0712 345 678" {
+		t.Fatalf("expected an empty region to leave numbers unchanged got %v err=%v", numbers, err)
+	}
+}
@@ -0,0 +1,117 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	DefaultChunkSize   = 1000 // DefaultChunkSize is the default number of recipients sent per bulk request
+	DefaultConcurrency = 4    // DefaultConcurrency is the default number of bulk requests in flight at once
+)
+
+// BatchOptions controls how SendBulkBatched splits and dispatches recipients
+type BatchOptions struct {
+	ChunkSize   int // ChunkSize is the maximum number of recipients per bulk request, defaults to DefaultChunkSize
+	Concurrency int // Concurrency is the maximum number of bulk requests in flight at once, defaults to DefaultConcurrency
+}
+
+// ChunkError records the failure of an individual chunk of a batched bulk send
+type ChunkError struct {
+	Index int      // Index of the chunk in the batch
+	To    []string // Recipients of the chunk
+	Err   error    // Err is the error returned for the chunk
+}
+
+// Error implements the error interface
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("sms: chunk %d (%d recipients): %s", e.Index, len(e.To), e.Err.Error())
+}
+
+// Unwrap returns the chunk's underlying error
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchResponse aggregates the responses of a batched bulk send
+type BatchResponse struct {
+	Responses  []Response    // Responses holds each chunk's response in chunk order, failed chunks have a zero Response
+	Recipients []Recipient   // Recipients merges the recipients of every successful chunk in chunk order
+	Errors     []*ChunkError // Errors holds the failure of each failed chunk in chunk order
+	Skipped    []string      // Skipped merges the recipients filtered out by Client.Suppressions
+}
+
+// chunk splits recipients into slices of at most size recipients
+func chunk(recipients []string, size int) [][]string {
+	chunks := [][]string{}
+	for start := 0; start < len(recipients); start += size {
+		end := min(start+size, len(recipients))
+		chunks = append(chunks, recipients[start:end])
+	}
+	return chunks
+}
+
+/*
+SendBulkBatched sends a bulk SMS to a very large list of recipients by splitting request.To into chunks
+and dispatching them with bounded concurrency.
+
+The returned BatchResponse always holds the results of every successful chunk. When any chunk fails the
+error joins every *ChunkError, which are also available in BatchResponse.Errors. Errors wrapping
+ErrSuppressionStore are joined as well, but their chunk's results are kept as it was sent.
+A message exceeding Client.MaxSegments fails with a single *SegmentLimitError before any chunk is sent.
+*/
+func (c *Client) SendBulkBatched(ctx context.Context, request *BulkRequest, options BatchOptions) (BatchResponse, error) {
+	if err := c.checkSegments(request.Message); err != nil {
+		return BatchResponse{Responses: []Response{}, Recipients: []Recipient{}}, err
+	}
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	chunks := chunk(request.To, chunkSize)
+	responses := make([]Response, len(chunks))
+	errs := make([]error, len(chunks))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, to := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, to []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			chunkRequest := *request
+			chunkRequest.To = to
+			responses[i], errs[i] = c.SendBulkWithContext(ctx, &chunkRequest)
+		}(i, to)
+	}
+	wg.Wait()
+
+	batch := BatchResponse{Responses: responses, Recipients: []Recipient{}}
+	joined := []error{}
+	for i, err := range errs {
+		if errors.Is(err, ErrSuppressionStore) {
+			joined = append(joined, err)
+		} else if err != nil {
+			chunkErr := &ChunkError{Index: i, To: chunks[i], Err: err}
+			batch.Errors = append(batch.Errors, chunkErr)
+			joined = append(joined, chunkErr)
+			continue
+		}
+		batch.Recipients = append(batch.Recipients, responses[i].Recipients...)
+		batch.Skipped = append(batch.Skipped, responses[i].Skipped...)
+	}
+	return batch, errors.Join(joined...)
+}
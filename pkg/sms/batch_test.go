@@ -0,0 +1,75 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestSendBulkBatched(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+
+		r.ParseForm()
+		to := strings.Split(r.PostForm.Get("to"), ",")
+		if len(to) > 3 {
+			t.Errorf("expected at most 3 recipients per chunk got %d", len(to))
+		}
+		if to[0] == "+254700000006" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		recipients := []string{}
+		for _, number := range to {
+			recipients = append(recipients, fmt.Sprintf(`{"statusCode":101,"number":"%s","status":"Success"}`, number))
+		}
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent to %d/%d","Recipients":[%s]}}`, len(to), len(to), strings.Join(recipients, ","))
+	}))
+	defer server.Close()
+
+	to := []string{}
+	for i := 0; i < 10; i++ {
+		to = append(to, fmt.Sprintf("+25470000000%d", i))
+	}
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+	response, err := client.SendBulkBatched(context.Background(), &BulkRequest{To: to, Message: "Hello AT"}, BatchOptions{ChunkSize: 3, Concurrency: 2})
+
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || chunkErr.Index != 2 || !core.IsValidationError(err) {
+		t.Fatalf("expected validation error for chunk 2 got %v", err)
+	}
+	if len(response.Responses) != 4 || len(response.Errors) != 1 {
+		t.Fatalf("expected 4 chunk responses and 1 error got %d and %d", len(response.Responses), len(response.Errors))
+	}
+	if len(response.Recipients) != 7 || response.Recipients[6].Number != "+254700000009" {
+		t.Fatalf("expected 7 merged recipients in order got %+v", response.Recipients)
+	}
+	if maxInFlight > 2 {
+		t.Fatalf("expected at most 2 requests in flight got %d", maxInFlight)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	chunks := chunk([]string{"a", "b", "c", "d", "e"}, 2)
+	if len(chunks) != 3 || len(chunks[2]) != 1 {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+	if len(chunk(nil, 2)) != 0 {
+		t.Fatalf("expected no chunks for no recipients")
+	}
+}
@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2026, 10, 16, 9, 7, 30, 0, time.UTC) // Friday
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC)},
+		{"0 8-17 * * 1-5", time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1", time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 13 * 5", time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2026, 10, 16, 10, 37, 30, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		recurrence, err := ParseCron(test.expr)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %s", test.expr, err.Error())
+		}
+		if next := recurrence.Next(from); !next.Equal(test.expected) {
+			t.Fatalf("%s: expected next=%s got next=%s", test.expr, test.expected, next)
+		}
+	}
+
+	never, _ := ParseCron("0 0 30 2 *")
+	if !never.Next(from).IsZero() {
+		t.Fatalf("expected February 30th to never fire")
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *", "@every -1h"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Fatalf("expected '%s' to be rejected", expr)
+		}
+	}
+}
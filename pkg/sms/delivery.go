@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// DeliveryReport represents a delivery report callback for a message sent through the SMS API
+type DeliveryReport struct {
+	Id            string // Id is the MessageId of the message, as returned in Recipient.MessageId
+	Status        string // Status of the message e.g "Sent", "Submitted", "Buffered", "Rejected", "Success", "Failed", "AbsentSubscriber", "Expired"
+	PhoneNumber   string // PhoneNumber is the recipient's phone number
+	NetworkCode   string // NetworkCode identifies the recipient's telco network e.g "63902" for Safaricom
+	FailureReason string // FailureReason is set when Status is "Rejected" or "Failed" e.g "InsufficientCredit"
+	RetryCount    int    // RetryCount is the number of times the message was retried
+}
+
+// DeliveryReportFunc handles a parsed delivery report. Returning an error responds with 500 so the callback is retried,
+// the error itself is not sent in the response so log it in the function if needed
+type DeliveryReportFunc func(ctx context.Context, report DeliveryReport) error
+
+/*
+DeliveryReportHandler returns an http.Handler for the SMS delivery report callback that parses each report and passes it to fn.
+
+Malformed callbacks are rejected with a 4xx response without calling fn.
+
+API Reference: https://developers.africastalking.com/docs/sms/notifications
+*/
+func DeliveryReportHandler(fn DeliveryReportFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		form, ok := parseCallback(w, r, "id", "status")
+		if !ok {
+			return
+		}
+		report := DeliveryReport{
+			Id:            form.Get("id"),
+			Status:        form.Get("status"),
+			PhoneNumber:   form.Get("phoneNumber"),
+			NetworkCode:   form.Get("networkCode"),
+			FailureReason: form.Get("failureReason"),
+		}
+		if retryCount := form.Get("retryCount"); retryCount != "" {
+			count, err := strconv.Atoi(retryCount)
+			if err != nil {
+				http.Error(w, "malformed callback: invalid retryCount", http.StatusBadRequest)
+				return
+			}
+			report.RetryCount = count
+		}
+		if err := fn(r.Context(), report); err != nil {
+			callbackFailed(w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
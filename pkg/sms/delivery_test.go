@@ -0,0 +1,82 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func postCallback(handler http.Handler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/callbacks/delivery", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeliveryReportHandler(t *testing.T) {
+	reports := []DeliveryReport{}
+	handler := DeliveryReportHandler(func(ctx context.Context, report DeliveryReport) error {
+		reports = append(reports, report)
+		return nil
+	})
+
+	rec := postCallback(handler, url.Values{
+		"id":            {"ATXid_1"},
+		"status":        {"Failed"},
+		"phoneNumber":   {"+254711000000"},
+		"networkCode":   {"63902"},
+		"failureReason": {"InsufficientCredit"},
+		"retryCount":    {"2"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status=200 got status=%d", rec.Code)
+	}
+	expected := DeliveryReport{
+		Id:            "ATXid_1",
+		Status:        "Failed",
+		PhoneNumber:   "+254711000000",
+		NetworkCode:   "63902",
+		FailureReason: "InsufficientCredit",
+		RetryCount:    2,
+	}
+	if len(reports) != 1 || reports[0] != expected {
+		t.Fatalf("expected report %+v got %+v", expected, reports)
+	}
+}
+
+func TestDeliveryReportHandlerRejectsMalformedCallbacks(t *testing.T) {
+	handler := DeliveryReportHandler(func(ctx context.Context, report DeliveryReport) error {
+		t.Fatalf("callback should not be called for malformed reports")
+		return nil
+	})
+
+	if rec := postCallback(handler, url.Values{"status": {"Success"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status=400 for missing id got status=%d", rec.Code)
+	}
+	if rec := postCallback(handler, url.Values{"id": {"ATXid_1"}, "status": {"Success"}, "retryCount": {"two"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status=400 for invalid retryCount got status=%d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/callbacks/delivery", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status=405 got status=%d", rec.Code)
+	}
+}
+
+func TestDeliveryReportHandlerCallbackError(t *testing.T) {
+	handler := DeliveryReportHandler(func(ctx context.Context, report DeliveryReport) error {
+		return errors.New("database unavailable")
+	})
+	rec := postCallback(handler, url.Values{"id": {"ATXid_1"}, "status": {"Success"}})
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status=500 got status=%d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "database") {
+		t.Fatalf("expected the callback error not to leak got body='%s'", rec.Body.String())
+	}
+}
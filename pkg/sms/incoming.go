@@ -0,0 +1,160 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IncomingMessage represents an incoming message callback sent to your short code or sender ID
+type IncomingMessage struct {
+	Id          string    // Id is the internal identifier Africa's Talking uses to store the message
+	LinkId      string    // LinkId is used to reply with an OnDemand premium message, see Reply
+	Text        string    // Text is the content of the message
+	To          string    // To is the short code or sender ID that received the message
+	From        string    // From is the sender's phone number
+	Date        time.Time // Date is when the message was received
+	NetworkCode string    // NetworkCode identifies the sender's telco network
+	Keyword     string    // Keyword is the keyword route the message matched on an IncomingMux, if any
+}
+
+// Reply returns a premium request that answers the message on demand through its LinkId
+func (m IncomingMessage) Reply(message string) *PremiumRequest {
+	return &PremiumRequest{
+		To:      []string{m.From},
+		From:    m.To,
+		Message: message,
+		Keyword: m.Keyword,
+		LinkId:  m.LinkId,
+	}
+}
+
+// IncomingMessageFunc handles an incoming message. Returning an error responds with 500 so the callback is retried,
+// the error itself is not sent in the response so log it in the function if needed
+type IncomingMessageFunc func(ctx context.Context, message IncomingMessage) error
+
+// incomingRoute dispatches messages to a short code, optionally filtered by keyword or pattern
+type incomingRoute struct {
+	shortCode string
+	keyword   string
+	pattern   *regexp.Regexp
+	fn        IncomingMessageFunc
+}
+
+/*
+IncomingMux is an http.Handler for the incoming messages callback that dispatches each message
+to the handler registered for its short code and keyword, similar to http.ServeMux.
+
+Routes are matched in order of specificity: keyword routes first, then pattern routes in the order
+they were registered, then short code routes. An empty short code matches every short code.
+Messages that match no route are passed to the NotFound handler, or acknowledged and dropped.
+
+API Reference: https://developers.africastalking.com/docs/sms/notifications
+*/
+type IncomingMux struct {
+	mu       sync.RWMutex
+	keywords []incomingRoute
+	patterns []incomingRoute
+	codes    []incomingRoute
+	notFound IncomingMessageFunc
+}
+
+// NewIncomingMux creates an empty IncomingMux
+func NewIncomingMux() *IncomingMux {
+	return &IncomingMux{}
+}
+
+// HandleKeyword routes messages to shortCode whose first word is keyword, compared case-insensitively
+func (m *IncomingMux) HandleKeyword(shortCode, keyword string, fn IncomingMessageFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keywords = append(m.keywords, incomingRoute{shortCode: shortCode, keyword: keyword, fn: fn})
+}
+
+// HandlePattern routes messages to shortCode whose text matches pattern
+func (m *IncomingMux) HandlePattern(shortCode string, pattern *regexp.Regexp, fn IncomingMessageFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.patterns = append(m.patterns, incomingRoute{shortCode: shortCode, pattern: pattern, fn: fn})
+}
+
+// HandleShortCode routes every message to shortCode that matched no keyword or pattern route
+func (m *IncomingMux) HandleShortCode(shortCode string, fn IncomingMessageFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes = append(m.codes, incomingRoute{shortCode: shortCode, fn: fn})
+}
+
+// NotFound sets the handler for messages that match no route
+func (m *IncomingMux) NotFound(fn IncomingMessageFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notFound = fn
+}
+
+// matchesShortCode reports whether the route applies to messages sent to shortCode
+func (r incomingRoute) matchesShortCode(shortCode string) bool {
+	return r.shortCode == "" || r.shortCode == shortCode
+}
+
+// route returns the handler for message and the keyword it matched
+func (m *IncomingMux) route(message IncomingMessage) (IncomingMessageFunc, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	firstWord := ""
+	if fields := strings.Fields(message.Text); len(fields) > 0 {
+		firstWord = fields[0]
+	}
+	for _, r := range m.keywords {
+		if r.matchesShortCode(message.To) && strings.EqualFold(r.keyword, firstWord) {
+			return r.fn, r.keyword
+		}
+	}
+	for _, r := range m.patterns {
+		if r.matchesShortCode(message.To) && r.pattern.MatchString(message.Text) {
+			return r.fn, ""
+		}
+	}
+	for _, r := range m.codes {
+		if r.matchesShortCode(message.To) {
+			return r.fn, ""
+		}
+	}
+	return m.notFound, ""
+}
+
+// ServeHTTP parses the incoming message callback and dispatches it to the matching handler
+func (m *IncomingMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	form, ok := parseCallback(w, r, "from", "to")
+	if !ok {
+		return
+	}
+	message := IncomingMessage{
+		Id:          form.Get("id"),
+		LinkId:      form.Get("linkId"),
+		Text:        form.Get("text"),
+		To:          form.Get("to"),
+		From:        form.Get("from"),
+		NetworkCode: form.Get("networkCode"),
+	}
+	if date := form.Get("date"); date != "" {
+		t, err := parseCallbackTime(date)
+		if err != nil {
+			http.Error(w, "malformed callback: invalid date", http.StatusBadRequest)
+			return
+		}
+		message.Date = t
+	}
+	fn, keyword := m.route(message)
+	if fn != nil {
+		message.Keyword = keyword
+		if err := fn(r.Context(), message); err != nil {
+			callbackFailed(w)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
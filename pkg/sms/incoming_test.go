@@ -0,0 +1,79 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncomingMux(t *testing.T) {
+	routed := map[string]IncomingMessage{}
+	record := func(name string) IncomingMessageFunc {
+		return func(ctx context.Context, message IncomingMessage) error {
+			routed[name] = message
+			return nil
+		}
+	}
+
+	mux := NewIncomingMux()
+	mux.HandleShortCode("28901", record("shortCode"))
+	mux.HandlePattern("28901", regexp.MustCompile(`^\d{4}$`), record("pin"))
+	mux.HandleKeyword("28901", "news", record("news"))
+	mux.HandleKeyword("", "stop", record("stop"))
+	mux.NotFound(record("notFound"))
+
+	messages := map[string]url.Values{
+		"news":      {"from": {"+254711000000"}, "to": {"28901"}, "text": {"NEWS sports"}, "linkId": {"link-1"}, "id": {"1"}, "date": {"2018-03-19 08:34:32"}},
+		"pin":       {"from": {"+254711000000"}, "to": {"28901"}, "text": {"1234"}},
+		"shortCode": {"from": {"+254711000000"}, "to": {"28901"}, "text": {"hello"}},
+		"stop":      {"from": {"+254711000000"}, "to": {"40404"}, "text": {"Stop"}},
+		"notFound":  {"from": {"+254711000000"}, "to": {"40404"}, "text": {"hello"}},
+	}
+	for name, form := range messages {
+		if rec := postCallback(mux, form); rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status=200 got status=%d", name, rec.Code)
+		}
+		if routed[name].Text != form.Get("text") {
+			t.Fatalf("expected '%s' to be routed to %s handler got %+v", form.Get("text"), name, routed)
+		}
+	}
+
+	if !routed["news"].Date.Equal(time.Date(2018, 3, 19, 8, 34, 32, 0, time.UTC)) {
+		t.Fatalf("expected date to be parsed got date=%s", routed["news"].Date)
+	}
+
+	reply := routed["news"].Reply("Today's sports news")
+	if reply.LinkId != "link-1" || reply.To[0] != "+254711000000" || reply.From != "28901" || reply.Keyword != "news" {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+}
+
+func TestIncomingMuxRejectsMalformedCallbacks(t *testing.T) {
+	mux := NewIncomingMux()
+	mux.NotFound(func(ctx context.Context, message IncomingMessage) error {
+		t.Fatalf("handler should not be called for malformed messages")
+		return nil
+	})
+	if rec := postCallback(mux, url.Values{"text": {"hello"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status=400 got status=%d", rec.Code)
+	}
+	if rec := postCallback(mux, url.Values{"from": {"+254711000000"}, "to": {"28901"}, "date": {"yesterday"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status=400 for an invalid date got status=%d", rec.Code)
+	}
+}
+
+func TestIncomingMuxCallbackError(t *testing.T) {
+	mux := NewIncomingMux()
+	mux.NotFound(func(ctx context.Context, message IncomingMessage) error {
+		return errors.New("database unavailable")
+	})
+	rec := postCallback(mux, url.Values{"from": {"+254711000000"}, "to": {"28901"}, "date": {"2018-03-19T08:34:32.000Z"}})
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "database") {
+		t.Fatalf("expected a generic 500 got status=%d body='%s'", rec.Code, rec.Body.String())
+	}
+}
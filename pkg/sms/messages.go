@@ -0,0 +1,99 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+// InboundMessage represents a message received by one of your short codes or sender IDs
+type InboundMessage struct {
+	Id     int64     `json:"id"`     // Id is the identifier of the message, used as lastReceivedId when fetching the next page
+	LinkId string    `json:"linkId"` // LinkId is used to reply with an OnDemand premium message through PremiumRequest.LinkId
+	Text   string    `json:"text"`   // Text is the content of the message
+	To     string    `json:"to"`     // To is the short code or sender ID that received the message
+	From   string    `json:"from"`   // From is the sender's phone number
+	Date   time.Time `json:"date"`   // Date is when the message was received
+}
+
+// fetchResponse is the wire format of the fetch messages response
+type fetchResponse struct {
+	SMSMessageData struct {
+		Messages []InboundMessage `json:"Messages"`
+	} `json:"SMSMessageData"`
+}
+
+/*
+FetchMessages fetches the inbox messages received after lastReceivedId.
+Pass 0 to start from the first message. An empty result means there are no newer messages.
+
+API Reference: https://developers.africastalking.com/docs/sms/fetch_messages
+*/
+func (c *Client) FetchMessages(ctx context.Context, lastReceivedId int64) ([]InboundMessage, error) {
+	query := url.Values{
+		"username":       {c.Username},
+		"lastReceivedId": {strconv.FormatInt(lastReceivedId, 10)},
+	}
+	url := core.ResolveURL(c.Endpoints, core.HostAPI, c.IsSandbox, messagingPath) + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := fetchResponse{}
+	if err := c.send(req, core.Endpoint(core.HostAPI, messagingPath), &res); err != nil {
+		return nil, err
+	}
+	if res.SMSMessageData.Messages == nil {
+		return []InboundMessage{}, nil
+	}
+	return res.SMSMessageData.Messages, nil
+}
+
+/*
+MessageIterator walks every inbox message page by page, fetching the next page when the current one is exhausted.
+
+	it := client.Messages(0)
+	for it.Next(ctx) {
+		fmt.Println(it.Message().Text)
+	}
+	if err := it.Err(); err != nil {
+		// handle error
+	}
+*/
+type MessageIterator struct {
+	pager pager[InboundMessage]
+}
+
+// Messages returns an iterator over the inbox messages received after lastReceivedId
+func (c *Client) Messages(lastReceivedId int64) *MessageIterator {
+	return &MessageIterator{pager: pager[InboundMessage]{
+		fetch:          c.FetchMessages,
+		id:             func(message InboundMessage) int64 { return message.Id },
+		lastReceivedId: lastReceivedId,
+	}}
+}
+
+// Next advances to the next message, fetching a new page when needed.
+// It returns false when there are no more messages or an error occurred
+func (it *MessageIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx)
+}
+
+// Message returns the current message
+func (it *MessageIterator) Message() InboundMessage {
+	return it.pager.current
+}
+
+// LastReceivedId returns the id of the newest message seen so far, to resume iteration later
+func (it *MessageIterator) LastReceivedId() int64 {
+	return it.pager.lastReceivedId
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *MessageIterator) Err() error {
+	return it.pager.err
+}
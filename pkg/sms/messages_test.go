@@ -0,0 +1,73 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestMessageIterator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Query().Get("username") != "sandbox" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		lastReceivedId, _ := strconv.Atoi(r.URL.Query().Get("lastReceivedId"))
+		messages := ""
+		// Serve two pages of two messages each
+		if lastReceivedId < 4 {
+			messages = fmt.Sprintf(
+				`{"id":%d,"linkId":"link-%d","text":"Hello","to":"28901","from":"+254711000000","date":"2018-03-19T08:34:32.000Z"},{"id":%d,"text":"World","to":"28901","from":"+254711000001","date":"2018-03-19T08:35:32.000Z"}`,
+				lastReceivedId+1, lastReceivedId+1, lastReceivedId+2,
+			)
+		}
+		fmt.Fprintf(w, `{"SMSMessageData":{"Messages":[%s]}}`, messages)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		ApiKey:    "api-key",
+		Username:  "sandbox",
+		Endpoints: core.AllHosts(server.URL),
+	}
+
+	it := client.Messages(0)
+	ids := []int64{}
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Message().Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %s", err.Error())
+	}
+	if len(ids) != 4 || ids[3] != 4 || it.LastReceivedId() != 4 {
+		t.Fatalf("expected ids 1-4 got %v", ids)
+	}
+
+	messages, err := client.FetchMessages(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("fetch messages failed: %s", err.Error())
+	}
+	if messages[0].LinkId != "link-1" || messages[0].Date.Minute() != 34 {
+		t.Fatalf("unexpected message %+v", messages[0])
+	}
+}
+
+func TestMessageIteratorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoints: core.AllHosts(server.URL)}
+	it := client.Messages(0)
+	if it.Next(context.Background()) {
+		t.Fatalf("expected iteration to stop")
+	}
+	if !core.IsAuthError(it.Err()) {
+		t.Fatalf("expected auth error got %v", it.Err())
+	}
+}
@@ -0,0 +1,148 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
+)
+
+// ErrSuppressionStore is returned together with a valid Response when the messages were sent but the recipients
+// the API reported as blacklisted could not be recorded in Client.Suppressions
+var ErrSuppressionStore = errors.New("sms: recording suppressed recipients")
+
+/*
+SuppressionStore records phone numbers that opted out of bulk SMS. Implementations must be safe for concurrent use.
+
+Phone numbers are stored and looked up in E.164, as sent by SendBulk once Client.Region normalised them.
+They are suppressed for every sender: an opt-out from one short code or alphanumeric stops bulk SMS
+from all of them. Use a separate store, and Client, per sender to scope opt-outs to the sender they were made from.
+*/
+type SuppressionStore interface {
+	// Suppress records that phoneNumber must no longer receive bulk SMS
+	Suppress(ctx context.Context, phoneNumber string) error
+	// Unsuppress allows phoneNumber to receive bulk SMS again, e.g after the user opts back in
+	Unsuppress(ctx context.Context, phoneNumber string) error
+	// Suppressed returns the subset of phoneNumbers that are suppressed
+	Suppressed(ctx context.Context, phoneNumbers []string) (map[string]bool, error)
+}
+
+// MemorySuppressionStore is an in-memory SuppressionStore
+type MemorySuppressionStore struct {
+	mu      sync.RWMutex
+	numbers map[string]bool
+}
+
+// NewMemorySuppressionStore creates a MemorySuppressionStore suppressing phoneNumbers
+func NewMemorySuppressionStore(phoneNumbers ...string) *MemorySuppressionStore {
+	s := &MemorySuppressionStore{numbers: make(map[string]bool)}
+	for _, phoneNumber := range phoneNumbers {
+		s.numbers[phoneNumber] = true
+	}
+	return s
+}
+
+// Suppress implements SuppressionStore
+func (s *MemorySuppressionStore) Suppress(ctx context.Context, phoneNumber string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.numbers[phoneNumber] = true
+	return nil
+}
+
+// Unsuppress implements SuppressionStore
+func (s *MemorySuppressionStore) Unsuppress(ctx context.Context, phoneNumber string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.numbers, phoneNumber)
+	return nil
+}
+
+// Suppressed implements SuppressionStore
+func (s *MemorySuppressionStore) Suppressed(ctx context.Context, phoneNumbers []string) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	suppressed := map[string]bool{}
+	for _, phoneNumber := range phoneNumbers {
+		if s.numbers[phoneNumber] {
+			suppressed[phoneNumber] = true
+		}
+	}
+	return suppressed, nil
+}
+
+// OptOut represents a bulk SMS opt-out callback
+type OptOut struct {
+	SenderId    string // SenderId is the short code or alphanumeric the user opted out of, see SuppressionStore for how it is scoped
+	PhoneNumber string // PhoneNumber is the phone number that opted out
+}
+
+/*
+OptOutHandler returns an http.Handler for the bulk SMS opt-out callback that records every
+opted out phone number in store in E.164, numbers that do not parse are stored as received.
+The phone number is suppressed for every sender.
+
+Malformed callbacks are rejected with a 4xx response and store failures with a generic 500 response.
+
+API Reference: https://developers.africastalking.com/docs/sms/notifications
+*/
+func OptOutHandler(store SuppressionStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		form, ok := parseCallback(w, r, "phoneNumber")
+		if !ok {
+			return
+		}
+		optOut := OptOut{SenderId: form.Get("senderId"), PhoneNumber: form.Get("phoneNumber")}
+		if number, err := phonenumber.Normalize(optOut.PhoneNumber, ""); err == nil {
+			optOut.PhoneNumber = number
+		}
+		if err := store.Suppress(r.Context(), optOut.PhoneNumber); err != nil {
+			callbackFailed(w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// filterSuppressed returns the recipients that are not suppressed and the ones that were skipped
+func (c *Client) filterSuppressed(ctx context.Context, to []string) ([]string, []string, error) {
+	if c.Suppressions == nil {
+		return to, nil, nil
+	}
+	suppressed, err := c.Suppressions.Suppressed(ctx, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(suppressed) == 0 {
+		return to, nil, nil
+	}
+	// The store may return numbers that were not asked for, only to bounds the slices
+	allowed := make([]string, 0, len(to))
+	skipped := []string{}
+	for _, phoneNumber := range to {
+		if suppressed[phoneNumber] {
+			skipped = append(skipped, phoneNumber)
+			continue
+		}
+		allowed = append(allowed, phoneNumber)
+	}
+	return allowed, skipped, nil
+}
+
+// suppressBlacklisted records recipients the API reported as blacklisted so that later sends skip them
+func (c *Client) suppressBlacklisted(ctx context.Context, recipients []Recipient) error {
+	if c.Suppressions == nil {
+		return nil
+	}
+	for _, recipient := range recipients {
+		if recipient.StatusCode == StatusUserInBlacklist {
+			if err := c.Suppressions.Suppress(ctx, recipient.Number); err != nil {
+				return fmt.Errorf("%w: %w", ErrSuppressionStore, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestOptOutHandler(t *testing.T) {
+	store := NewMemorySuppressionStore()
+	handler := OptOutHandler(store)
+
+	if rec := postCallback(handler, url.Values{"senderId": {"AFRICASTKNG"}, "phoneNumber": {"+254700000001"}}); rec.Code != http.StatusOK {
+		t.Fatalf("expected status=200 got status=%d", rec.Code)
+	}
+	if rec := postCallback(handler, url.Values{"senderId": {"AFRICASTKNG"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status=400 got status=%d", rec.Code)
+	}
+	// Numbers are stored in E.164 so that they match the numbers SendBulk filters
+	postCallback(handler, url.Values{"senderId": {"AFRICASTKNG"}, "phoneNumber": {"254700000002"}})
+	suppressed, _ := store.Suppressed(context.Background(), []string{"+254700000001", "+254700000002", "+254700000003"})
+	if len(suppressed) != 2 || !suppressed["+254700000001"] || !suppressed["+254700000002"] {
+		t.Fatalf("expected opted out number to be suppressed got %v", suppressed)
+	}
+}
+
+func TestSendBulkSkipsSuppressedNumbers(t *testing.T) {
+	requested := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		to := strings.Split(r.PostForm.Get("to"), ",")
+		requested = append(requested, to...)
+		recipients := []string{}
+		for _, number := range to {
+			statusCode := StatusSent
+			if number == "+254700000003" {
+				statusCode = StatusUserInBlacklist
+			}
+			recipients = append(recipients, fmt.Sprintf(`{"statusCode":%d,"number":"%s","status":"%s"}`, statusCode, number, statusCode))
+		}
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[%s]}}`, strings.Join(recipients, ","))
+	}))
+	defer server.Close()
+
+	store := NewMemorySuppressionStore("+254700000001")
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Suppressions: store}
+	request := &BulkRequest{To: []string{"+254700000001", "+254700000002", "+254700000003"}, Message: "Hello AT"}
+
+	response, err := client.SendBulk(request)
+	if err != nil {
+		t.Fatalf("bulk sms request failed: %s", err.Error())
+	}
+	if len(response.Skipped) != 1 || response.Skipped[0] != "+254700000001" || len(requested) != 2 {
+		t.Fatalf("expected suppressed number to be skipped got skipped=%v requested=%v", response.Skipped, requested)
+	}
+	if len(request.To) != 3 {
+		t.Fatalf("expected the caller's request to be left untouched")
+	}
+
+	// The blacklisted recipient is suppressed for the next send
+	response, _ = client.SendBulk(request)
+	if len(response.Skipped) != 2 || len(requested) != 3 {
+		t.Fatalf("expected blacklisted number to be suppressed got skipped=%v requested=%v", response.Skipped, requested)
+	}
+
+	store.Unsuppress(context.Background(), "+254700000003")
+	response, _ = client.SendBulk(&BulkRequest{To: []string{"+254700000001"}, Message: "Hello AT"})
+	if len(response.Skipped) != 1 || len(requested) != 3 {
+		t.Fatalf("expected a fully suppressed request to skip the API got requested=%v", requested)
+	}
+}
+
+// failingSuppressionStore suppresses nothing and fails to record opt-outs
+type failingSuppressionStore struct{ *MemorySuppressionStore }
+
+func (failingSuppressionStore) Suppress(ctx context.Context, phoneNumber string) error {
+	return errors.New("disk full")
+}
+
+func TestSuppressionStoreFailures(t *testing.T) {
+	store := failingSuppressionStore{NewMemorySuppressionStore()}
+	rec := postCallback(OptOutHandler(store), url.Values{"senderId": {"AFRICASTKNG"}, "phoneNumber": {"+254700000001"}})
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "disk full") {
+		t.Fatalf("expected a generic 500 got status=%d body=%q", rec.Code, rec.Body.String())
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[{"statusCode":%d,"number":"+254700000001","status":"UserInBlacklist"}]}}`, StatusUserInBlacklist)
+	}))
+	defer server.Close()
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Suppressions: store}
+	response, err := client.SendBulk(&BulkRequest{To: []string{"+254700000001"}, Message: "Hello AT"})
+	if !errors.Is(err, ErrSuppressionStore) || len(response.Recipients) != 1 {
+		t.Fatalf("expected the store failure to be returned with the response got %v", err)
+	}
+}
+
+func TestSuppressedRecipientsAreTerminal(t *testing.T) {
+	sends := map[string]int{}
+	server := outboxServer(t, map[string]int{}, sends)
+	defer server.Close()
+	ctx := context.Background()
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Suppressions: NewMemorySuppressionStore("+254700000002")}
+
+	store := NewMemoryStore()
+	outbox := NewOutbox(client, store)
+	outbox.Enqueue(ctx, &BulkRequest{To: []string{"+254700000001", "+254700000002"}, Message: "Hello AT"})
+	if sent, err := outbox.Drain(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected 1 sent message got sent=%d err=%v", sent, err)
+	}
+	suppressed, _ := store.List(ctx, OutboxSuppressed)
+	if len(suppressed) != 1 || suppressed[0].To != "+254700000002" || sends["+254700000002"] != 0 {
+		t.Fatalf("expected the suppressed message to be marked as suppressed got %+v", suppressed)
+	}
+
+	results, err := client.SendTemplate(ctx, &TemplateRequest{
+		Template:   template.Must(template.New("hello").Parse("Hello {{.}}")),
+		Recipients: []TemplateRecipient{{PhoneNumber: "+254700000001", Data: "Jane"}, {PhoneNumber: "+254700000002", Data: "John"}},
+	})
+	if err != nil || results[0].Suppressed || !results[1].Suppressed || results[1].Err != nil {
+		t.Fatalf("expected the suppressed recipient to be reported got %+v err=%v", results, err)
+	}
+}
+
+// extraSuppressionStore reports numbers that were not asked for as suppressed
+type extraSuppressionStore struct{ *MemorySuppressionStore }
+
+func (extraSuppressionStore) Suppressed(ctx context.Context, phoneNumbers []string) (map[string]bool, error) {
+	return map[string]bool{"+254700000001": true, "+254700000008": true, "+254700000009": true}, nil
+}
+
+func TestFilterSuppressedIgnoresExtraNumbers(t *testing.T) {
+	client := &Client{Suppressions: extraSuppressionStore{NewMemorySuppressionStore()}}
+	allowed, skipped, err := client.filterSuppressed(context.Background(), []string{"+254700000001", "+254700000002"})
+	if err != nil || len(allowed) != 1 || allowed[0] != "+254700000002" || len(skipped) != 1 {
+		t.Fatalf("unexpected allowed=%v skipped=%v err=%v", allowed, skipped, err)
+	}
+}
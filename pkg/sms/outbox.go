@@ -0,0 +1,355 @@
+package sms
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
+)
+
+// OutboxStatus is the delivery state of a message in an Outbox
+type OutboxStatus string
+
+const (
+	OutboxPending    OutboxStatus = "pending"    // OutboxPending messages are waiting to be sent
+	OutboxSending    OutboxStatus = "sending"    // OutboxSending messages were handed to the API and await its response
+	OutboxSent       OutboxStatus = "sent"       // OutboxSent messages were accepted by the API, see OutboxMessage.MessageId
+	OutboxFailed     OutboxStatus = "failed"     // OutboxFailed messages failed permanently or ran out of attempts
+	OutboxSuppressed OutboxStatus = "suppressed" // OutboxSuppressed messages were not sent because Client.Suppressions filtered their recipient out
+	OutboxUnknown    OutboxStatus = "unknown"    // OutboxUnknown messages were in flight during a crash or failed ambiguously and may or may not have been sent
+)
+
+// OutboxMessage is a message to a single recipient recorded in an Outbox
+type OutboxMessage struct {
+	Id            string        `json:"id"`            // Id identifies the message in the Store
+	To            string        `json:"to"`            // To is the recipient's phone number
+	Message       string        `json:"message"`       // Message is the contents of the sms
+	From          string        `json:"from"`          // From is your registered short code or alphanumerics
+	BulkSMSMode   bool          `json:"bulkSMSMode"`   // BulkSMSMode is sent as BulkRequest.BulkSMSMode
+	Enqueue       bool          `json:"enqueue"`       // Enqueue is sent as BulkRequest.Enqueue
+	RetryDuration time.Duration `json:"retryDuration"` // RetryDuration is sent as BulkRequest.RetryDuration
+	Status        OutboxStatus  `json:"status"`        // Status is the delivery state of the message
+	MessageId     string        `json:"messageId"`     // MessageId is returned by the API once the message is sent
+	StatusCode    StatusCode    `json:"statusCode"`    // StatusCode is the last status returned by the API for the recipient
+	Attempts      int           `json:"attempts"`      // Attempts is the number of times the message was handed to the API
+	LastError     string        `json:"lastError"`     // LastError describes the last failed attempt
+	NextAttemptAt time.Time     `json:"nextAttemptAt"` // NextAttemptAt is the earliest time a pending message is sent again
+	CreatedAt     time.Time     `json:"createdAt"`     // CreatedAt is when the message was enqueued
+	UpdatedAt     time.Time     `json:"updatedAt"`     // UpdatedAt is when the message was last saved
+}
+
+// Store persists the messages of an Outbox. Implementations must be safe for concurrent use
+type Store interface {
+	// Save inserts or replaces messages by Id
+	Save(ctx context.Context, messages ...OutboxMessage) error
+	// Due returns up to limit pending messages whose NextAttemptAt is not after now, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error)
+	// List returns every message with status, oldest first
+	List(ctx context.Context, status OutboxStatus) ([]OutboxMessage, error)
+}
+
+/*
+Outbox records messages in a Store before sending them so that a campaign survives process restarts.
+
+Messages are marked as sending before they are handed to the API and as sent with their MessageId once
+accepted. After a crash, Recover moves messages left in flight to OutboxUnknown instead of sending them
+again. Messages whose outcome is ambiguous, because the connection failed or the API answered with a
+server error after the request was sent or left the recipient out of its response, are marked as
+OutboxUnknown as well, and the Client's retry policy is not used. Only failures the API reported, or
+that happened before the request was sent, are retried, so a message is never sent twice. Reconcile
+OutboxUnknown messages with delivery reports before sending them again.
+*/
+type Outbox struct {
+	Client      *Client          // Client sends the messages
+	Store       Store            // Store persists the messages
+	MaxAttempts int              // MaxAttempts before a message is marked as failed, defaults to 5
+	Backoff     time.Duration    // Backoff is the delay before a failed message is retried, multiplied by its attempts. Defaults to 30 seconds
+	BatchSize   int              // BatchSize is the number of due messages sent per drain iteration, defaults to 100
+	Now         func() time.Time // Now returns the current time, defaults to time.Now
+}
+
+// NewOutbox creates an Outbox sending messages recorded in store through client
+func NewOutbox(client *Client, store Store) *Outbox {
+	return &Outbox{Client: client, Store: store}
+}
+
+func (o *Outbox) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+func (o *Outbox) maxAttempts() int {
+	if o.MaxAttempts > 0 {
+		return o.MaxAttempts
+	}
+	return 5
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	backoff := o.Backoff
+	if backoff <= 0 {
+		backoff = 30 * time.Second
+	}
+	return backoff * time.Duration(attempts)
+}
+
+// newId returns a random identifier for outbox messages and schedules
+func newId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("sms: generating id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Enqueue records a pending message for every recipient of request
+func (o *Outbox) Enqueue(ctx context.Context, request *BulkRequest) ([]OutboxMessage, error) {
+	now := o.now()
+	messages := make([]OutboxMessage, len(request.To))
+	for i, to := range request.To {
+		id, err := newId()
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = OutboxMessage{
+			Id:            id,
+			To:            to,
+			Message:       request.Message,
+			From:          request.From,
+			BulkSMSMode:   request.BulkSMSMode,
+			Enqueue:       request.Enqueue,
+			RetryDuration: request.RetryDuration,
+			Status:        OutboxPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+	if err := o.Store.Save(ctx, messages...); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Recover marks messages left in flight by a crashed process as OutboxUnknown and returns them.
+// Call it once at start up before draining
+func (o *Outbox) Recover(ctx context.Context) ([]OutboxMessage, error) {
+	messages, err := o.Store.List(ctx, OutboxSending)
+	if err != nil {
+		return nil, err
+	}
+	now := o.now()
+	for i := range messages {
+		messages[i].Status = OutboxUnknown
+		messages[i].UpdatedAt = now
+	}
+	if err := o.Store.Save(ctx, messages...); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// outboxKey identifies the due messages that can share a bulk request
+type outboxKey struct {
+	message       string
+	from          string
+	bulkSMSMode   bool
+	enqueue       bool
+	retryDuration time.Duration
+}
+
+// outboxGroup is a set of due messages sharing the same text and sending options
+type outboxGroup struct {
+	outboxKey
+	messages []OutboxMessage
+}
+
+// Drain sends due messages until none are left, returning the number of messages sent.
+// Messages that fail transiently are rescheduled and picked up by a later Drain
+func (o *Outbox) Drain(ctx context.Context) (int, error) {
+	batchSize := o.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	sent := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		due, err := o.Store.Due(ctx, o.now(), batchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(due) == 0 {
+			return sent, nil
+		}
+
+		groups := []*outboxGroup{}
+		index := map[outboxKey]*outboxGroup{}
+		for _, message := range due {
+			key := outboxKey{
+				message:       message.Message,
+				from:          message.From,
+				bulkSMSMode:   message.BulkSMSMode,
+				enqueue:       message.Enqueue,
+				retryDuration: message.RetryDuration,
+			}
+			group, ok := index[key]
+			if !ok {
+				group = &outboxGroup{outboxKey: key}
+				index[key] = group
+				groups = append(groups, group)
+			}
+			group.messages = append(group.messages, message)
+		}
+		for _, group := range groups {
+			n, err := o.send(ctx, group)
+			sent += n
+			if err != nil {
+				return sent, err
+			}
+		}
+	}
+}
+
+// send hands a group of messages to the API and records the outcome for each message
+func (o *Outbox) send(ctx context.Context, group *outboxGroup) (int, error) {
+	now := o.now()
+	// Normalise each number on its own so that a number that does not parse fails alone instead of the whole group
+	messages := make([]OutboxMessage, 0, len(group.messages))
+	rejected := []OutboxMessage{}
+	to := make([]string, 0, len(group.messages))
+	for _, message := range group.messages {
+		message.UpdatedAt = now
+		number, err := phonenumber.NormalizeAll([]string{message.To}, o.Client.Region)
+		if err != nil {
+			message.Status = OutboxFailed
+			message.LastError = err.Error()
+			rejected = append(rejected, message)
+			continue
+		}
+		message.Status = OutboxSending
+		message.Attempts++
+		messages = append(messages, message)
+		to = append(to, number[0])
+	}
+	group.messages = messages
+	if err := o.Store.Save(ctx, append(rejected, messages...)...); err != nil {
+		return 0, err
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	// A retried bulk request may deliver the messages twice, ambiguous failures are left for the caller to reconcile
+	client := *o.Client
+	client.Retry = nil
+	response, err := client.SendBulkWithContext(ctx, &BulkRequest{
+		To:            to,
+		Message:       group.message,
+		From:          group.from,
+		BulkSMSMode:   group.bulkSMSMode,
+		Enqueue:       group.enqueue,
+		RetryDuration: group.retryDuration,
+	})
+	if err != nil && ctx.Err() != nil {
+		// Leave the messages in flight, Recover decides what to do with them
+		return 0, err
+	}
+	// A suppression store failure comes with the response of messages that were sent, report it once their outcome is recorded
+	sendErr := err
+	if errors.Is(err, ErrSuppressionStore) {
+		err = nil
+	}
+	statuses := map[string]Recipient{}
+	for _, recipient := range response.Recipients {
+		statuses[recipient.Number] = recipient
+	}
+	skipped := map[string]bool{}
+	for _, number := range response.Skipped {
+		skipped[number] = true
+	}
+
+	sent := 0
+	now = o.now()
+	for i := range group.messages {
+		message := &group.messages[i]
+		message.UpdatedAt = now
+		recipient, ok := statuses[to[i]]
+		switch {
+		case err != nil && !notSent(err):
+			message.Status = OutboxUnknown
+			message.LastError = err.Error()
+		case err != nil:
+			o.fail(message, err.Error(), isPermanentSendError(err))
+		case skipped[to[i]]:
+			message.Status = OutboxSuppressed
+			message.LastError = ""
+		case !ok:
+			message.Status = OutboxUnknown
+			message.LastError = ErrRecipientMissing.Error()
+		case recipient.StatusCode.IsSuccess():
+			message.Status = OutboxSent
+			message.StatusCode = recipient.StatusCode
+			message.MessageId = recipient.MessageId
+			message.LastError = ""
+			sent++
+		default:
+			message.StatusCode = recipient.StatusCode
+			o.fail(message, recipient.Status, recipient.StatusCode.IsPermanentFailure())
+		}
+	}
+	if err := o.Store.Save(ctx, group.messages...); err != nil {
+		return sent, err
+	}
+	if core.IsAuthError(err) || errors.Is(sendErr, ErrSuppressionStore) {
+		// Every following request would fail the same way, stop draining until the credentials or store are fixed
+		return sent, sendErr
+	}
+	return sent, nil
+}
+
+// fail records a failed attempt, rescheduling the message unless the failure is permanent or it ran out of attempts
+func (o *Outbox) fail(message *OutboxMessage, reason string, permanent bool) {
+	message.LastError = reason
+	if permanent || message.Attempts >= o.maxAttempts() {
+		message.Status = OutboxFailed
+		return
+	}
+	message.Status = OutboxPending
+	message.NextAttemptAt = message.UpdatedAt.Add(o.backoff(message.Attempts))
+}
+
+// notSent reports whether err means the bulk request was rejected or never sent, so that sending it again cannot
+// deliver a message twice. Connection failures and server errors after the request was sent are ambiguous
+func notSent(err error) bool {
+	var apiErr *core.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode < http.StatusInternalServerError
+	}
+	var (
+		limitErr *SegmentLimitError
+		parseErr *phonenumber.ParseError
+		opErr    *net.OpError
+	)
+	return errors.As(err, &limitErr) || errors.As(err, &parseErr) || errors.Is(err, core.ErrRateLimited) ||
+		(errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// isPermanentSendError reports whether sending the same request again will fail the same way
+func isPermanentSendError(err error) bool {
+	var (
+		limitErr *SegmentLimitError
+		parseErr *phonenumber.ParseError
+	)
+	return errors.As(err, &limitErr) || errors.As(err, &parseErr) || core.IsValidationError(err)
+}
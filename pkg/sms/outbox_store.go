@@ -0,0 +1,225 @@
+package sms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store, useful for tests and processes that do not need to survive restarts
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages map[string]OutboxMessage
+	order    []string
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string]OutboxMessage)}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(ctx context.Context, messages ...OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.save(messages...)
+	return nil
+}
+
+func (s *MemoryStore) save(messages ...OutboxMessage) {
+	for _, message := range messages {
+		if _, ok := s.messages[message.Id]; !ok {
+			s.order = append(s.order, message.Id)
+		}
+		s.messages[message.Id] = message
+	}
+}
+
+// Due implements Store
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := []OutboxMessage{}
+	for _, id := range s.order {
+		message := s.messages[id]
+		if message.Status == OutboxPending && !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(ctx context.Context, status OutboxStatus) ([]OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := []OutboxMessage{}
+	for _, id := range s.order {
+		if message := s.messages[id]; message.Status == status {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+/*
+FileStore is a Store backed by a JSON-lines file.
+
+Every save appends the full message to the file and syncs it to disk, and opening the file replays it
+keeping the latest version of each message, so the outbox resumes where it left off after a crash.
+Compact rewrites the file with only the latest version of each message.
+*/
+type FileStore struct {
+	mu     sync.Mutex
+	memory *MemoryStore
+	path   string
+	file   *os.File
+}
+
+// OpenFileStore opens or creates the JSON-lines file at path and replays the messages it holds
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{memory: NewMemoryStore(), path: path}
+	size, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	// Drop a torn final line so that the next save starts on a line of its own
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+// replay loads the messages recorded in the file and returns the offset at which its last complete line ends
+func (s *FileStore) replay() (int64, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	offset := int64(0)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A final line without a newline is left behind by a crash mid-write, its save never returned
+			return offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		offset += int64(len(data))
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		message := OutboxMessage{}
+		if err := json.Unmarshal(data, &message); err != nil {
+			return 0, fmt.Errorf("sms: outbox %s line %d: %w", s.path, line, err)
+		}
+		s.memory.save(message)
+	}
+}
+
+// Save implements Store
+func (s *FileStore) Save(ctx context.Context, messages ...OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := []byte{}
+	for _, message := range messages {
+		line, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if _, err := s.file.Write(buf); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	return s.memory.Save(ctx, messages...)
+}
+
+// Due implements Store
+func (s *FileStore) Due(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error) {
+	return s.memory.Due(ctx, now, limit)
+}
+
+// List implements Store
+func (s *FileStore) List(ctx context.Context, status OutboxStatus) ([]OutboxMessage, error) {
+	return s.memory.List(ctx, status)
+}
+
+// Compact rewrites the file with only the latest version of each message
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	for _, id := range s.memory.order {
+		line, err := json.Marshal(s.memory.messages[id])
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(line, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+	s.file.Close()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	return nil
+}
+
+// Close closes the underlying file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,248 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+// outboxServer accepts every recipient except the statuses overridden by number, and counts sends per number
+func outboxServer(t *testing.T, statuses map[string]int, sends map[string]int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		recipients := []string{}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, number := range strings.Split(r.PostForm.Get("to"), ",") {
+			sends[number]++
+			statusCode := 101
+			if code, ok := statuses[number]; ok {
+				statusCode = code
+			}
+			recipients = append(recipients, fmt.Sprintf(`{"statusCode":%d,"number":"%s","status":"%s","messageId":"id-%s"}`, statusCode, number, StatusCode(statusCode), number))
+		}
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[%s]}}`, strings.Join(recipients, ","))
+	}))
+}
+
+func TestOutboxDrain(t *testing.T) {
+	statuses := map[string]int{"+254700000002": 403, "+254700000003": 500}
+	sends := map[string]int{}
+	server := outboxServer(t, statuses, sends)
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	outbox := NewOutbox(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}, store)
+	outbox.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := outbox.Enqueue(ctx, &BulkRequest{To: []string{"+254700000001", "+254700000002", "+254700000003"}, Message: "Hello AT"}); err != nil {
+		t.Fatalf("enqueue failed: %s", err.Error())
+	}
+	sent, err := outbox.Drain(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("expected 1 sent message got sent=%d err=%v", sent, err)
+	}
+
+	failed, _ := store.List(ctx, OutboxFailed)
+	if len(failed) != 1 || failed[0].To != "+254700000002" || failed[0].StatusCode != StatusInvalidPhoneNumber {
+		t.Fatalf("expected invalid phone number to fail permanently got %+v", failed)
+	}
+	pending, _ := store.List(ctx, OutboxPending)
+	if len(pending) != 1 || pending[0].Attempts != 1 || !pending[0].NextAttemptAt.After(now) {
+		t.Fatalf("expected gateway error to be rescheduled got %+v", pending)
+	}
+
+	// The rescheduled message is not due until its backoff elapses
+	if sent, _ := outbox.Drain(ctx); sent != 0 {
+		t.Fatalf("expected nothing to be due got sent=%d", sent)
+	}
+	delete(statuses, "+254700000003")
+	now = now.Add(time.Minute)
+	if sent, _ := outbox.Drain(ctx); sent != 1 {
+		t.Fatalf("expected rescheduled message to be sent got sent=%d", sent)
+	}
+
+	messages, _ := store.List(ctx, OutboxSent)
+	if len(messages) != 2 || messages[0].MessageId != "id-+254700000001" {
+		t.Fatalf("expected sent messages to record their MessageId got %+v", messages)
+	}
+	if sends["+254700000001"] != 1 || sends["+254700000003"] != 2 {
+		t.Fatalf("unexpected sends %v", sends)
+	}
+}
+
+func TestOutboxResumesAfterCrash(t *testing.T) {
+	sends := map[string]int{}
+	server := outboxServer(t, map[string]int{}, sends)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ctx := context.Background()
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open store failed: %s", err.Error())
+	}
+	messages, _ := NewOutbox(client, store).Enqueue(ctx, &BulkRequest{To: []string{"+254700000001", "+254700000002"}, Message: "Hello AT"})
+	// Simulate a crash after the first message was handed to the API, leaving a torn line behind
+	inFlight := messages[0]
+	inFlight.Status = OutboxSending
+	store.Save(ctx, inFlight)
+	store.Close()
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	file.WriteString(`{"id":"torn`)
+	file.Close()
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen store failed: %s", err.Error())
+	}
+	outbox := NewOutbox(client, store)
+	recovered, err := outbox.Recover(ctx)
+	if err != nil || len(recovered) != 1 || recovered[0].Id != inFlight.Id {
+		t.Fatalf("expected in flight message to be recovered got %+v err=%v", recovered, err)
+	}
+	if sent, err := outbox.Drain(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected remaining message to be sent got sent=%d err=%v", sent, err)
+	}
+	if sends["+254700000001"] != 0 || sends["+254700000002"] != 1 {
+		t.Fatalf("expected in flight message not to be sent again got %v", sends)
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatalf("compact failed: %s", err.Error())
+	}
+	store.Close()
+	store, _ = OpenFileStore(path)
+	defer store.Close()
+	unknown, _ := store.List(ctx, OutboxUnknown)
+	sent, _ := store.List(ctx, OutboxSent)
+	if len(unknown) != 1 || len(sent) != 1 {
+		t.Fatalf("expected compacted store to keep the latest state got unknown=%d sent=%d", len(unknown), len(sent))
+	}
+}
+
+func TestFileStoreSavesAfterTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ctx := context.Background()
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open store failed: %s", err.Error())
+	}
+	store.Save(ctx, OutboxMessage{Id: "1", Status: OutboxPending})
+	store.Close()
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	file.WriteString(`{"id":"torn`)
+	file.Close()
+
+	for i := 0; i < 2; i++ {
+		store, err = OpenFileStore(path)
+		if err != nil {
+			t.Fatalf("reopen %d failed: %s", i+1, err.Error())
+		}
+		store.Save(ctx, OutboxMessage{Id: fmt.Sprint(i + 2), Status: OutboxPending})
+		store.Close()
+	}
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("final reopen failed: %s", err.Error())
+	}
+	defer store.Close()
+	if pending, _ := store.List(ctx, OutboxPending); len(pending) != 3 {
+		t.Fatalf("expected 3 messages got %+v", pending)
+	}
+}
+
+func TestOutboxDoesNotResendAmbiguousFailures(t *testing.T) {
+	var mu sync.Mutex
+	requests := []url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		requests = append(requests, r.PostForm)
+		mu.Unlock()
+		if r.PostForm.Get("message") == "gateway" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[{"statusCode":101,"number":"+254700000001","status":"Success","messageId":"id-1"}]}}`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Retry: &core.RetryPolicy{MaxAttempts: 3}}
+	outbox := NewOutbox(client, store)
+	outbox.Enqueue(ctx, &BulkRequest{To: []string{"+254700000001", "+254700000002"}, Message: "Hello AT", Enqueue: true})
+	outbox.Enqueue(ctx, &BulkRequest{To: []string{"+254700000003"}, Message: "gateway"})
+	if _, err := outbox.Drain(ctx); err != nil {
+		t.Fatalf("drain failed: %s", err.Error())
+	}
+
+	unknown, _ := store.List(ctx, OutboxUnknown)
+	if len(unknown) != 2 || unknown[0].To != "+254700000002" || unknown[1].To != "+254700000003" {
+		t.Fatalf("expected ambiguous failures to be left unknown got %+v", unknown)
+	}
+	if pending, _ := store.List(ctx, OutboxPending); len(pending) != 0 {
+		t.Fatalf("expected nothing to be rescheduled got %+v", pending)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected each group to be sent once got %d requests", len(requests))
+	}
+	if requests[0].Get("enqueue") != "1" || requests[1].Get("enqueue") != "0" {
+		t.Fatalf("expected request options to be kept per group got %v", requests)
+	}
+}
+
+func TestOutboxNormalizesNumbers(t *testing.T) {
+	sends := map[string]int{}
+	server := outboxServer(t, map[string]int{}, sends)
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	outbox := NewOutbox(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Region: "KE"}, store)
+	outbox.Enqueue(ctx, &BulkRequest{To: []string{"0712 345 678"}, Message: "Hello AT"})
+	if sent, err := outbox.Drain(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected 1 sent message got sent=%d err=%v", sent, err)
+	}
+	messages, _ := store.List(ctx, OutboxSent)
+	if len(messages) != 1 || messages[0].MessageId != "id-+254712345678" || sends["+254712345678"] != 1 {
+		t.Fatalf("expected the normalised number to be matched got %+v sends=%v", messages, sends)
+	}
+}
+
+func TestOutboxFailsOnlyInvalidNumbers(t *testing.T) {
+	sends := map[string]int{}
+	server := outboxServer(t, map[string]int{}, sends)
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	outbox := NewOutbox(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Region: "KE"}, store)
+	outbox.Enqueue(ctx, &BulkRequest{To: []string{"0712 345 678", "0712 345 679", "not-a-number"}, Message: "Hello AT"})
+	if sent, err := outbox.Drain(ctx); err != nil || sent != 2 {
+		t.Fatalf("expected 2 sent messages got sent=%d err=%v", sent, err)
+	}
+	failed, _ := store.List(ctx, OutboxFailed)
+	if len(failed) != 1 || failed[0].To != "not-a-number" || failed[0].Attempts != 0 || failed[0].LastError == "" {
+		t.Fatalf("expected only the invalid number to fail got %+v", failed)
+	}
+	if sends["+254712345678"] != 1 || sends["+254712345679"] != 1 {
+		t.Fatalf("expected the valid numbers to be sent got %v", sends)
+	}
+}
@@ -0,0 +1,41 @@
+package sms
+
+import "context"
+
+// pager walks a lastReceivedId paginated listing, fetching the next page when the current one is exhausted
+type pager[T any] struct {
+	fetch          func(ctx context.Context, lastReceivedId int64) ([]T, error)
+	id             func(item T) int64
+	lastReceivedId int64
+	page           []T
+	current        T
+	done           bool
+	err            error
+}
+
+// next advances to the next item, returning false when there are no more items or an error occurred
+func (p *pager[T]) next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+	if len(p.page) == 0 {
+		page, err := p.fetch(ctx, p.lastReceivedId)
+		if err != nil {
+			p.err = err
+			p.done = true
+			return false
+		}
+		// Stop on an empty page, or a page that does not advance past lastReceivedId
+		if len(page) == 0 || p.id(page[len(page)-1]) <= p.lastReceivedId {
+			p.done = true
+			return false
+		}
+		p.page = page
+	}
+	p.current = p.page[0]
+	p.page = p.page[1:]
+	if id := p.id(p.current); id > p.lastReceivedId {
+		p.lastReceivedId = id
+	}
+	return true
+}
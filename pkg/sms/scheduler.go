@@ -0,0 +1,277 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrScheduleNotFound is returned when cancelling or updating a schedule that does not exist
+var ErrScheduleNotFound = errors.New("sms: schedule not found")
+
+// Clock tells the Scheduler the time and lets tests fake it
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Schedule is a bulk SMS that is sent at a given time, once or repeatedly
+type Schedule struct {
+	Id         string      `json:"id"`         // Id identifies the schedule
+	Request    BulkRequest `json:"request"`    // Request is the bulk SMS to send
+	SendAt     time.Time   `json:"sendAt"`     // SendAt is when the schedule fires next
+	Cron       string      `json:"cron"`       // Cron is the recurrence of the schedule, see ParseCron. Empty for one-off schedules
+	LastSentAt time.Time   `json:"lastSentAt"` // LastSentAt is when the schedule last fired
+}
+
+// ScheduleStore persists schedules. Implementations must be safe for concurrent use
+type ScheduleStore interface {
+	// Save inserts or replaces a schedule by Id
+	Save(ctx context.Context, schedule Schedule) error
+	// Update replaces an existing schedule by Id, returning ErrScheduleNotFound if it does not exist
+	Update(ctx context.Context, schedule Schedule) error
+	// Delete removes a schedule, returning ErrScheduleNotFound if it does not exist
+	Delete(ctx context.Context, id string) error
+	// List returns every schedule
+	List(ctx context.Context) ([]Schedule, error)
+}
+
+// MemoryScheduleStore is an in-memory ScheduleStore
+type MemoryScheduleStore struct {
+	mu        sync.Mutex
+	schedules map[string]Schedule
+}
+
+// NewMemoryScheduleStore creates an empty MemoryScheduleStore
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{schedules: make(map[string]Schedule)}
+}
+
+// Save implements ScheduleStore
+func (s *MemoryScheduleStore) Save(ctx context.Context, schedule Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[schedule.Id] = schedule
+	return nil
+}
+
+// Update implements ScheduleStore
+func (s *MemoryScheduleStore) Update(ctx context.Context, schedule Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[schedule.Id]; !ok {
+		return ErrScheduleNotFound
+	}
+	s.schedules[schedule.Id] = schedule
+	return nil
+}
+
+// Delete implements ScheduleStore
+func (s *MemoryScheduleStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+// List implements ScheduleStore
+func (s *MemoryScheduleStore) List(ctx context.Context) ([]Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules := make([]Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// ScheduleResultFunc is called after a schedule fires with the result of its bulk send
+type ScheduleResultFunc func(schedule Schedule, response Response, err error)
+
+/*
+Scheduler sends bulk SMS at a given time or on a cron-like recurrence.
+
+Schedules are persisted through a ScheduleStore and advanced (or removed, for one-off schedules) before
+they are sent, so a crash while firing never sends the same occurrence twice.
+*/
+type Scheduler struct {
+	Client       *Client            // Client sends the scheduled messages
+	Store        ScheduleStore      // Store persists the schedules
+	Clock        Clock              // Clock tells the time, defaults to the system clock
+	OnResult     ScheduleResultFunc // OnResult is called after every send (optional)
+	PollInterval time.Duration      // PollInterval bounds how long Run sleeps before checking the store again, defaults to one minute
+
+	wakeOnce sync.Once
+	wake     chan struct{}
+}
+
+// NewScheduler creates a Scheduler sending schedules persisted in store through client
+func NewScheduler(client *Client, store ScheduleStore) *Scheduler {
+	return &Scheduler{Client: client, Store: store}
+}
+
+func (s *Scheduler) clock() Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return systemClock{}
+}
+
+// wakeup returns the channel used to wake a running scheduler
+func (s *Scheduler) wakeup() chan struct{} {
+	s.wakeOnce.Do(func() {
+		s.wake = make(chan struct{}, 1)
+	})
+	return s.wake
+}
+
+// notify wakes a running scheduler so that it picks up a new schedule
+func (s *Scheduler) notify() {
+	select {
+	case s.wakeup() <- struct{}{}:
+	default:
+	}
+}
+
+// ScheduleAt schedules request to be sent once at sendAt
+func (s *Scheduler) ScheduleAt(ctx context.Context, request *BulkRequest, sendAt time.Time) (Schedule, error) {
+	id, err := newId()
+	if err != nil {
+		return Schedule{}, err
+	}
+	schedule := Schedule{Id: id, Request: *request, SendAt: sendAt}
+	if err := s.Store.Save(ctx, schedule); err != nil {
+		return Schedule{}, err
+	}
+	s.notify()
+	return schedule, nil
+}
+
+// ScheduleCron schedules request to be sent on the recurrence described by cron, see ParseCron
+func (s *Scheduler) ScheduleCron(ctx context.Context, request *BulkRequest, cron string) (Schedule, error) {
+	recurrence, err := ParseCron(cron)
+	if err != nil {
+		return Schedule{}, err
+	}
+	sendAt := recurrence.Next(s.clock().Now())
+	if sendAt.IsZero() {
+		return Schedule{}, fmt.Errorf("sms: cron expression %q never fires", cron)
+	}
+	id, err := newId()
+	if err != nil {
+		return Schedule{}, err
+	}
+	schedule := Schedule{Id: id, Request: *request, SendAt: sendAt, Cron: cron}
+	if err := s.Store.Save(ctx, schedule); err != nil {
+		return Schedule{}, err
+	}
+	s.notify()
+	return schedule, nil
+}
+
+// Cancel removes a schedule so that it never fires again
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	return s.Store.Delete(ctx, id)
+}
+
+// List returns every schedule ordered by the time it fires next
+func (s *Scheduler) List(ctx context.Context) ([]Schedule, error) {
+	schedules, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].SendAt.Before(schedules[j].SendAt)
+	})
+	return schedules, nil
+}
+
+// RunDue sends every schedule that is due and returns the number of schedules fired.
+// Send failures are reported to OnResult, the returned error is reserved for store failures
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	schedules, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.clock().Now()
+	fired := 0
+	for _, schedule := range schedules {
+		if schedule.SendAt.After(now) {
+			break
+		}
+		if err := s.advance(ctx, schedule, now); err != nil {
+			if errors.Is(err, ErrScheduleNotFound) {
+				// Cancelled concurrently
+				continue
+			}
+			return fired, err
+		}
+		request := schedule.Request
+		response, err := s.Client.SendBulkWithContext(ctx, &request)
+		schedule.LastSentAt = now
+		if s.OnResult != nil {
+			s.OnResult(schedule, response, err)
+		}
+		fired++
+	}
+	return fired, nil
+}
+
+// advance removes a fired one-off schedule or moves a recurring schedule to its next occurrence
+func (s *Scheduler) advance(ctx context.Context, schedule Schedule, now time.Time) error {
+	if schedule.Cron == "" {
+		return s.Store.Delete(ctx, schedule.Id)
+	}
+	recurrence, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	schedule.LastSentAt = now
+	schedule.SendAt = recurrence.Next(now)
+	if schedule.SendAt.IsZero() {
+		return s.Store.Delete(ctx, schedule.Id)
+	}
+	// Update rather than save, a schedule cancelled since it was listed must not come back
+	return s.Store.Update(ctx, schedule)
+}
+
+// Run fires due schedules until ctx is done, sleeping until the next schedule is due
+func (s *Scheduler) Run(ctx context.Context) error {
+	pollInterval := s.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	for {
+		if _, err := s.RunDue(ctx); err != nil {
+			return err
+		}
+		schedules, err := s.List(ctx)
+		if err != nil {
+			return err
+		}
+		wait := pollInterval
+		if len(schedules) > 0 {
+			if until := schedules[0].SendAt.Sub(s.clock().Now()); until < wait {
+				wait = max(until, 0)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wakeup():
+		case <-s.clock().After(wait):
+		}
+	}
+}
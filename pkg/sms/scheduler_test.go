@@ -0,0 +1,180 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+// fakeClock is a Clock whose time only moves when the test advances it
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, ch)
+	return ch
+}
+
+// advance moves the clock forward and wakes every waiter
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, ch := range c.waiters {
+		ch <- c.now
+	}
+	c.waiters = nil
+}
+
+func smsServer(sent chan<- string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sent <- r.PostForm.Get("message")
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[{"statusCode":101,"number":"%s","status":"Success"}]}}`, r.PostForm.Get("to"))
+	}))
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	sent := make(chan string, 10)
+	server := smsServer(sent)
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)}
+	scheduler := NewScheduler(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}, NewMemoryScheduleStore())
+	scheduler.Clock = clock
+	results := 0
+	scheduler.OnResult = func(schedule Schedule, response Response, err error) {
+		if err != nil {
+			t.Errorf("scheduled send failed: %s", err.Error())
+		}
+		results++
+	}
+	ctx := context.Background()
+	request := &BulkRequest{To: []string{"+254700000001"}}
+
+	request.Message = "once"
+	scheduler.ScheduleAt(ctx, request, clock.now.Add(30*time.Minute))
+	request.Message = "hourly"
+	hourly, _ := scheduler.ScheduleCron(ctx, request, "@hourly")
+	request.Message = "cancelled"
+	cancelled, _ := scheduler.ScheduleAt(ctx, request, clock.now.Add(10*time.Minute))
+
+	if err := scheduler.Cancel(ctx, cancelled.Id); err != nil {
+		t.Fatalf("cancel failed: %s", err.Error())
+	}
+	if err := scheduler.Cancel(ctx, cancelled.Id); !errors.Is(err, ErrScheduleNotFound) {
+		t.Fatalf("expected ErrScheduleNotFound got %v", err)
+	}
+	schedules, _ := scheduler.List(ctx)
+	if len(schedules) != 2 || schedules[0].Request.Message != "once" {
+		t.Fatalf("expected schedules ordered by send time got %+v", schedules)
+	}
+
+	if fired, _ := scheduler.RunDue(ctx); fired != 0 {
+		t.Fatalf("expected nothing to be due got fired=%d", fired)
+	}
+	clock.advance(time.Hour)
+	if fired, _ := scheduler.RunDue(ctx); fired != 2 {
+		t.Fatalf("expected 2 schedules to fire got fired=%d", fired)
+	}
+	if <-sent != "once" || <-sent != "hourly" {
+		t.Fatalf("unexpected send order")
+	}
+
+	schedules, _ = scheduler.List(ctx)
+	if len(schedules) != 1 || schedules[0].Id != hourly.Id || !schedules[0].SendAt.Equal(time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected only the recurring schedule to remain, advanced to its next occurrence got %+v", schedules)
+	}
+	if results != 2 {
+		t.Fatalf("expected OnResult to be called twice got %d", results)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	sent := make(chan string, 10)
+	server := smsServer(sent)
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)}
+	scheduler := NewScheduler(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}, NewMemoryScheduleStore())
+	scheduler.Clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- scheduler.Run(ctx) }()
+
+	scheduler.ScheduleAt(ctx, &BulkRequest{To: []string{"+254700000001"}, Message: "reminder"}, clock.Now().Add(5*time.Minute))
+	// Let Run pick up the new schedule and wait on the clock before advancing it
+	for i := 0; i < 100; i++ {
+		clock.advance(time.Minute)
+		select {
+		case message := <-sent:
+			if message != "reminder" {
+				t.Fatalf("unexpected message '%s'", message)
+			}
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled got %v", err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("scheduled message was never sent")
+}
+
+// cancellingStore cancels a schedule right after it is listed, as a concurrent Cancel would
+type cancellingStore struct {
+	*MemoryScheduleStore
+	cancel string
+}
+
+func (s *cancellingStore) List(ctx context.Context) ([]Schedule, error) {
+	schedules, err := s.MemoryScheduleStore.List(ctx)
+	if s.cancel != "" {
+		s.Delete(ctx, s.cancel)
+		s.cancel = ""
+	}
+	return schedules, err
+}
+
+func TestSchedulerCancelDuringTick(t *testing.T) {
+	sent := make(chan string, 10)
+	server := smsServer(sent)
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)}
+	store := &cancellingStore{MemoryScheduleStore: NewMemoryScheduleStore()}
+	scheduler := NewScheduler(&Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}, store)
+	scheduler.Clock = clock
+	ctx := context.Background()
+
+	hourly, _ := scheduler.ScheduleCron(ctx, &BulkRequest{To: []string{"+254700000001"}, Message: "hourly"}, "@hourly")
+	clock.advance(time.Hour)
+	store.cancel = hourly.Id
+	if fired, err := scheduler.RunDue(ctx); err != nil || fired != 0 {
+		t.Fatalf("expected the cancelled schedule not to fire got fired=%d err=%v", fired, err)
+	}
+	if schedules, _ := scheduler.List(ctx); len(schedules) != 0 {
+		t.Fatalf("expected the cancelled schedule to stay cancelled got %+v", schedules)
+	}
+}
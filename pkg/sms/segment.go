@@ -0,0 +1,138 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoding is the character encoding an SMS is sent with
+type Encoding string
+
+const (
+	EncodingGSM7 Encoding = "GSM-7" // EncodingGSM7 packs each character in 7 bits, extended characters take two
+	EncodingUCS2 Encoding = "UCS-2" // EncodingUCS2 is used as soon as a message has a character outside the GSM-7 alphabet
+)
+
+const (
+	gsm7SingleSegment    = 160 // septets in a single segment GSM-7 message
+	gsm7MultipartSegment = 153 // septets in each segment of a multipart GSM-7 message
+	ucs2SingleSegment    = 70  // UTF-16 code units in a single segment UCS-2 message
+	ucs2MultipartSegment = 67  // UTF-16 code units in each segment of a multipart UCS-2 message
+)
+
+// gsm7Basic is the GSM 03.38 basic character set, excluding the escape character
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extended is the GSM 03.38 extension table, each character is sent as an escape sequence of two septets
+const gsm7Extended = "\f^{}\\[~]|€"
+
+// SegmentInfo describes how a message is encoded and split into segments
+type SegmentInfo struct {
+	Encoding             Encoding // Encoding the message is sent with
+	Segments             int      // Segments is the number of SMS segments the message is billed as
+	Units                int      // Units is the length of the message in septets (GSM-7) or UTF-16 code units (UCS-2)
+	CharactersPerSegment int      // CharactersPerSegment is the number of units that fit in each segment
+	Remaining            int      // Remaining is the number of units still available in the last segment
+	Parts                []string // Parts holds the text of each segment
+	NonGSMCharacters     []rune   // NonGSMCharacters lists the distinct characters that forced UCS-2 encoding
+}
+
+// gsm7Units returns the number of septets r takes in GSM-7, or 0 if r is not in the GSM-7 alphabet
+func gsm7Units(r rune) int {
+	if strings.ContainsRune(gsm7Basic, r) {
+		return 1
+	}
+	if strings.ContainsRune(gsm7Extended, r) {
+		return 2
+	}
+	return 0
+}
+
+// ucs2Units returns the number of UTF-16 code units r takes in UCS-2
+func ucs2Units(r rune) int {
+	if r > 0xFFFF {
+		return 2 // surrogate pair
+	}
+	return 1
+}
+
+/*
+CalculateSegments detects whether message is sent as GSM-7 or UCS-2 and how many segments it is billed as.
+
+Escape sequences and surrogate pairs are never split across segments.
+*/
+func CalculateSegments(message string) SegmentInfo {
+	info := SegmentInfo{Encoding: EncodingGSM7, Parts: []string{}}
+	seen := map[rune]bool{}
+	for _, r := range message {
+		if gsm7Units(r) == 0 && !seen[r] {
+			seen[r] = true
+			info.NonGSMCharacters = append(info.NonGSMCharacters, r)
+		}
+	}
+	units := gsm7Units
+	single, multipart := gsm7SingleSegment, gsm7MultipartSegment
+	if len(info.NonGSMCharacters) > 0 {
+		info.Encoding = EncodingUCS2
+		units = ucs2Units
+		single, multipart = ucs2SingleSegment, ucs2MultipartSegment
+	}
+
+	for _, r := range message {
+		info.Units += units(r)
+	}
+	if info.Units == 0 {
+		info.CharactersPerSegment = single
+		info.Remaining = single
+		return info
+	}
+
+	info.CharactersPerSegment = single
+	if info.Units > single {
+		info.CharactersPerSegment = multipart
+	}
+	part := strings.Builder{}
+	partUnits := 0
+	for _, r := range message {
+		size := units(r)
+		if partUnits+size > info.CharactersPerSegment {
+			info.Parts = append(info.Parts, part.String())
+			part.Reset()
+			partUnits = 0
+		}
+		part.WriteRune(r)
+		partUnits += size
+	}
+	info.Parts = append(info.Parts, part.String())
+	info.Segments = len(info.Parts)
+	info.Remaining = info.CharactersPerSegment - partUnits
+	return info
+}
+
+// SegmentLimitError is returned when a message needs more segments than Client.MaxSegments allows
+type SegmentLimitError struct {
+	Info        SegmentInfo // Info describes the rejected message
+	MaxSegments int         // MaxSegments is the configured segment budget
+}
+
+// Error implements the error interface
+func (e *SegmentLimitError) Error() string {
+	message := fmt.Sprintf("sms: message needs %d %s segments, more than the limit of %d", e.Info.Segments, e.Info.Encoding, e.MaxSegments)
+	if len(e.Info.NonGSMCharacters) > 0 {
+		message += fmt.Sprintf(" (UCS-2 forced by %q)", string(e.Info.NonGSMCharacters))
+	}
+	return message
+}
+
+// checkSegments enforces the client's segment budget on message
+func (c *Client) checkSegments(message string) error {
+	if c.MaxSegments <= 0 {
+		return nil
+	}
+	info := CalculateSegments(message)
+	if info.Segments > c.MaxSegments {
+		return &SegmentLimitError{Info: info, MaxSegments: c.MaxSegments}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCalculateSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		encoding  Encoding
+		segments  int
+		units     int
+		remaining int
+	}{
+		{"empty", "", EncodingGSM7, 0, 0, 160},
+		{"single gsm", "Hello AT", EncodingGSM7, 1, 8, 152},
+		{"full gsm", strings.Repeat("a", 160), EncodingGSM7, 1, 160, 0},
+		{"multipart gsm", strings.Repeat("a", 161), EncodingGSM7, 2, 161, 145},
+		{"extended gsm", "Price: 10€ [promo]", EncodingGSM7, 1, 21, 139},
+		{"ucs2", "Habari 👋", EncodingUCS2, 1, 9, 61},
+		{"multipart ucs2", strings.Repeat("ш", 71), EncodingUCS2, 2, 71, 63},
+	}
+	for _, test := range tests {
+		info := CalculateSegments(test.message)
+		if info.Encoding != test.encoding || info.Segments != test.segments || info.Units != test.units || info.Remaining != test.remaining {
+			t.Fatalf("%s: unexpected segment info %+v", test.name, info)
+		}
+		if strings.Join(info.Parts, "") != test.message {
+			t.Fatalf("%s: expected parts to join back into the message got %q", test.name, info.Parts)
+		}
+	}
+}
+
+func TestCalculateSegmentsDoesNotSplitEscapes(t *testing.T) {
+	// 152 septets followed by an extended character must move the escape sequence to the next segment
+	info := CalculateSegments(strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10))
+	if info.Segments != 2 || info.Parts[1] != "€"+strings.Repeat("a", 10) {
+		t.Fatalf("unexpected parts %q", info.Parts)
+	}
+
+	info = CalculateSegments("café ✓ ✓ 👋")
+	if string(info.NonGSMCharacters) != "✓👋" {
+		t.Fatalf("expected offending characters '✓👋' got '%s'", string(info.NonGSMCharacters))
+	}
+}
+
+func TestMaxSegments(t *testing.T) {
+	client := &Client{MaxSegments: 1}
+	_, err := client.SendBulk(&BulkRequest{To: []string{"+254700000001"}, Message: strings.Repeat("Hi 👋 ", 20)})
+
+	var limitErr *SegmentLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected *SegmentLimitError got %v", err)
+	}
+	if limitErr.Info.Encoding != EncodingUCS2 || limitErr.Info.Segments != 2 {
+		t.Fatalf("unexpected segment info %+v", limitErr.Info)
+	}
+
+	to := []string{"+254700000001", "+254700000002", "+254700000003"}
+	batch, err := client.SendBulkBatched(context.Background(), &BulkRequest{To: to, Message: strings.Repeat("Hi 👋 ", 20)}, BatchOptions{ChunkSize: 1})
+	if !errors.As(err, &limitErr) || len(batch.Errors) != 0 {
+		t.Fatalf("expected a single *SegmentLimitError before chunking got %v", err)
+	}
+}
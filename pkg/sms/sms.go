@@ -1,22 +1,27 @@
 /*
-Package SMS sends Bulk and Premium SMS
+Package SMS sends Bulk and Premium SMS, fetches inbox messages and manages premium subscriptions
 
 Bulk SMS API Reference: https://developers.africastalking.com/docs/sms/sending/bulk
 
 Premium SMS API Reference: https://developers.africastalking.com/docs/sms/sending/premium
+
+Fetch Messages API Reference: https://developers.africastalking.com/docs/sms/fetch_messages
+
+Premium Subscriptions API Reference: https://developers.africastalking.com/docs/sms/premium_subscriptions/create
 */
 package sms
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
 )
 
 // BulkRequest represents the request body for the bulk SMS request
@@ -44,38 +49,37 @@ type PremiumRequest struct {
 
 // Recipient represents a recipient who was included in the original request
 type Recipient struct {
-	Status     string // Status indicates whether the SMS was sent to the recipient or not
-	StatusCode uint16 // StatusCode is the status of the request
-	Number     string // Number is the recipient's phone number
-	Cost       string // Cost is the amount incurred to send this SMS
-	MessageId  string // MessageId received when the sms was sent
+	Status     string     `json:"status"`     // Status indicates whether the SMS was sent to the recipient or not
+	StatusCode StatusCode `json:"statusCode"` // StatusCode is the status of the request e.g StatusSent
+	Number     string     `json:"number"`     // Number is the recipient's phone number
+	Cost       core.Money `json:"cost"`       // Cost is the amount incurred to send this SMS
+	MessageId  string     `json:"messageId"`  // MessageId received when the sms was sent
 }
 
 // Response represents the response from Africa's Talking API
 type Response struct {
-	Message    string // Message is the summary of the total number of recipients the sms was sent to and total cost
-	Recipients []Recipient
+	Message    string      `json:"Message"`    // Message is the summary of the total number of recipients the sms was sent to and total cost
+	Recipients []Recipient `json:"Recipients"` // Recipients lists the delivery status of each recipient
+	Skipped    []string    `json:"-"`          // Skipped lists the recipients filtered out by Client.Suppressions
 }
 
-// Client represents the HTTP client responsible for communicating with Africa's Talking API
+// Client represents the HTTP client responsible for communicating with Africa's Talking API.
+// A Client is safe for concurrent use by multiple goroutines
 type Client struct {
-	ApiKey    string       // API Key provided by Africa's talking
-	Username  string       // Your Africa's talking application username
-	IsSandbox bool         // IsSandbox specifies whether to use sandbox or live environment
-	Client    *http.Client // HTTP client for making requests to Africa's Talking API
+	ApiKey       string            // API Key provided by Africa's talking
+	Username     string            // Your Africa's talking application username
+	IsSandbox    bool              // IsSandbox specifies whether to use sandbox or live environment
+	Client       *http.Client      // HTTP client for making requests to Africa's Talking API. Defaults to core.DefaultHTTPClient
+	Endpoints    core.Resolver     // Endpoints resolves API URLs, defaults to Africa's Talking's hosts for the environment
+	Retry        *core.RetryPolicy // Retry controls how transient failures are retried, nil disables retries
+	Limiter      *core.RateLimiter // Limiter throttles requests client-side, nil disables rate limiting
+	MaxSegments  int               // MaxSegments rejects messages billed as more segments with a *SegmentLimitError, 0 disables the check
+	Suppressions SuppressionStore  // Suppressions filters opted out phone numbers out of bulk SMS, nil sends to every recipient
+	Region       string            // Region normalises phone numbers to E.164 before sending, reading local numbers as numbers of this ISO country code e.g "KE". Empty sends numbers as given
 }
 
-// getUrl returns Africa's Talking SMS URL based on the specified client environment
-func getUrl(isSandbox bool, service string) string {
-	if isSandbox {
-		return "https://api.sandbox.africastalking.com/version1/messaging"
-	}
-	if service == "bulk" {
-		return "https://api.africastalking.com/version1/messaging"
-	}
-	// Return url for premium SMS
-	return "https://content.africastalking.com/version1/messaging"
-}
+// messagingPath is the path of the SMS API on both the bulk (api) and premium (content) hosts
+const messagingPath = "/version1/messaging"
 
 // getBulkRequestBody generates the request body for the bulk SMS HTTP request to Africa's Talking API
 func getBulkRequestBody(request *BulkRequest, username string, isSandbox bool) url.Values {
@@ -107,6 +111,7 @@ func getPremiumRequestBody(request *PremiumRequest, username string, isSandbox b
 		"username":  {username},
 		"to":        {strings.Join(request.To, ",")},
 		"message":   {request.Message},
+		"keyword":   {request.Keyword},
 		"linkId":    {request.LinkId},
 		"requestId": {request.RequestId},
 	}
@@ -131,79 +136,100 @@ func setHeaders(request *http.Request, apiKey string) {
 
 // formatResponse maps response from Africa's Talking API to the internal Response type
 func formatResponse(response *http.Response) (Response, error) {
-	res := make(map[string]interface{})
-	decoder := json.NewDecoder(response.Body)
-	if err := decoder.Decode(&res); err != nil {
+	res := struct {
+		SMSMessageData Response `json:"SMSMessageData"`
+	}{}
+	if err := core.DecodeJSON(response, &res); err != nil {
 		return Response{}, err
 	}
-
-	data := res["SMSMessageData"].(map[string]interface{})
-	recepientsData := data["Recipients"].([]interface{})
-	recipients := []Recipient{}
-
-	for _, v := range recepientsData {
-		data := v.(map[string]interface{})
-		recipient := Recipient{
-			StatusCode: uint16(data["statusCode"].(float64)),
-			Number:     data["number"].(string),
-			Cost:       data["cost"].(string),
-			MessageId:  data["messageId"].(string),
-			Status:     data["status"].(string),
-		}
-		recipients = append(recipients, recipient)
+	if res.SMSMessageData.Recipients == nil {
+		res.SMSMessageData.Recipients = []Recipient{}
 	}
-
-	return Response{
-		Message:    data["Message"].(string),
-		Recipients: recipients,
-	}, nil
+	return res.SMSMessageData, nil
 }
 
-// sendBulk sends Bulk SMS using the Africa's Talking API
+// SendBulk sends Bulk SMS using the Africa's Talking API
 func (c *Client) SendBulk(request *BulkRequest) (Response, error) {
-	c.Client = &http.Client{}
+	return c.SendBulkWithContext(context.Background(), request)
+}
+
+// SendBulkWithContext is like SendBulk but aborts the request when ctx is cancelled or its deadline expires.
+// An error wrapping ErrSuppressionStore is returned with the response of messages that were sent
+func (c *Client) SendBulkWithContext(ctx context.Context, request *BulkRequest) (Response, error) {
+	if err := c.checkSegments(request.Message); err != nil {
+		return Response{}, err
+	}
+	numbers, err := phonenumber.NormalizeAll(request.To, c.Region)
+	if err != nil {
+		return Response{}, err
+	}
+	to, skipped, err := c.filterSuppressed(ctx, numbers)
+	if err != nil {
+		return Response{}, err
+	}
+	if len(to) == 0 && len(skipped) > 0 {
+		return Response{Recipients: []Recipient{}, Skipped: skipped}, nil
+	}
+	filtered := *request
+	filtered.To = to
+	request = &filtered
 	data := getBulkRequestBody(request, c.Username, c.IsSandbox)
-	url := getUrl(c.IsSandbox, "bulk")
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	url := core.ResolveURL(c.Endpoints, core.HostAPI, c.IsSandbox, messagingPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return Response{}, err
+	}
 	setHeaders(req, c.ApiKey)
-	resp, err := c.Client.Do(req)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostAPI, messagingPath))
 	if err != nil {
 		return Response{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return Response{}, err
-		}
-		res := string(bodyBytes)
-		return Response{}, errors.New(res)
+	if err := core.CheckResponse(resp); err != nil {
+		return Response{}, err
 	}
 
-	return formatResponse(resp)
+	response, err := formatResponse(resp)
+	if err != nil {
+		return Response{}, err
+	}
+	response.Skipped = skipped
+	// The messages were already sent, the response is returned with the store failure so that it is not lost
+	return response, c.suppressBlacklisted(ctx, response.Recipients)
 }
 
 // SendPremium sends Premium SMS using the Africa's Talking API
 func (c *Client) SendPremium(request *PremiumRequest) (Response, error) {
-	c.Client = &http.Client{}
-	data := getPremiumRequestBody(request, c.Username, c.IsSandbox)
-	url := getUrl(c.IsSandbox, "premium")
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	return c.SendPremiumWithContext(context.Background(), request)
+}
+
+// SendPremiumWithContext is like SendPremium but aborts the request when ctx is cancelled or its deadline expires
+func (c *Client) SendPremiumWithContext(ctx context.Context, request *PremiumRequest) (Response, error) {
+	if err := c.checkSegments(request.Message); err != nil {
+		return Response{}, err
+	}
+	to, err := phonenumber.NormalizeAll(request.To, c.Region)
+	if err != nil {
+		return Response{}, err
+	}
+	normalized := *request
+	normalized.To = to
+	data := getPremiumRequestBody(&normalized, c.Username, c.IsSandbox)
+	url := core.ResolveURL(c.Endpoints, core.HostContent, c.IsSandbox, messagingPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return Response{}, err
+	}
 	setHeaders(req, c.ApiKey)
-	resp, err := c.Client.Do(req)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostContent, messagingPath))
 	if err != nil {
 		return Response{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return Response{}, err
-		}
-		res := string(bodyBytes)
-		return Response{}, errors.New(res)
+	if err := core.CheckResponse(resp); err != nil {
+		return Response{}, err
 	}
 
 	return formatResponse(resp)
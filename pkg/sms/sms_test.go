@@ -1,7 +1,13 @@
 package sms
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -54,3 +60,122 @@ func TestSendPremium(t *testing.T) {
 		t.Fatalf("expected status = 'success' got status = '%s'", status)
 	}
 }
+
+func TestSendBulkWithCancelledContext(t *testing.T) {
+	client := &Client{
+		ApiKey:    os.Getenv("AT_API_KEY"),
+		Username:  os.Getenv("AT_USERNAME"),
+		IsSandbox: true,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.SendBulkWithContext(ctx, &BulkRequest{
+		To:      []string{"+254706496885"},
+		Message: "Hello AT",
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled got %v", err)
+	}
+}
+
+// roundTripFunc adapts a function into an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSendBulkUsesInjectedClient(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		body := `{"SMSMessageData":{"Message":"Sent to 1/1 Total Cost: KES 0.8000","Recipients":[{"statusCode":101,"number":"+254706496885","cost":"KES 0.8000","status":"Success","messageId":"ATXid_1"}]}}`
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+	client := &Client{
+		ApiKey:    "api-key",
+		Username:  "sandbox",
+		IsSandbox: true,
+		Client:    httpClient,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.SendBulk(&BulkRequest{To: []string{"+254706496885"}, Message: "Hello AT"}); err != nil {
+				t.Errorf("bulk sms request failed: %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if client.Client != httpClient {
+		t.Fatalf("expected injected http client to be preserved")
+	}
+	if calls != 10 {
+		t.Fatalf("expected calls=10 got calls=%d", calls)
+	}
+}
+
+func TestFormatResponse(t *testing.T) {
+	body := `{"SMSMessageData":{"Message":"Sent to 0/1 Total Cost: 0","Recipients":[{"statusCode":403,"number":"+2547","cost":null,"status":"InvalidPhoneNumber"}]}}`
+	response, err := formatResponse(&http.Response{Body: io.NopCloser(strings.NewReader(body))})
+	if err != nil {
+		t.Fatalf("failed to format response: %s", err.Error())
+	}
+	recipient := response.Recipients[0]
+	if recipient.StatusCode != 403 || !recipient.Cost.IsZero() || recipient.MessageId != "" {
+		t.Fatalf("unexpected recipient %+v", recipient)
+	}
+
+	_, err = formatResponse(&http.Response{Body: io.NopCloser(strings.NewReader("InvalidSenderId"))})
+	if err == nil {
+		t.Fatalf("expected decode error for non JSON body")
+	}
+}
+
+func TestSendBulkNormalizesNumbers(t *testing.T) {
+	var to string
+	client := &Client{
+		ApiKey:    "api-key",
+		Username:  "sandbox",
+		IsSandbox: true,
+		Region:    "KE",
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req.ParseForm()
+			to = req.PostForm.Get("to")
+			body := `{"SMSMessageData":{"Message":"Sent to 2/2","Recipients":[]}}`
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		})},
+	}
+
+	request := &BulkRequest{To: []string{"0712 345 678", "254722000000"}, Message: "Hello AT"}
+	if _, err := client.SendBulk(request); err != nil {
+		t.Fatal(err)
+	}
+	if to != "+254712345678,+254722000000" {
+		t.Fatalf("expected normalised recipients got to='%s'", to)
+	}
+	if request.To[0] != "0712 345 678" {
+		t.Fatalf("expected the caller's request to be left untouched")
+	}
+
+	to = ""
+	if _, err := client.SendBulk(&BulkRequest{To: []string{"0712"}, Message: "Hello AT"}); err == nil || to != "" {
+		t.Fatalf("expected invalid numbers to be rejected before sending")
+	}
+}
@@ -0,0 +1,69 @@
+package sms
+
+import "strconv"
+
+// StatusCode is the status of an SMS sent to an individual recipient, see Recipient.StatusCode
+type StatusCode uint16
+
+const (
+	StatusProcessed             StatusCode = 100 // The message was processed
+	StatusSent                  StatusCode = 101 // The message was sent to the telco
+	StatusQueued                StatusCode = 102 // The message was queued for sending
+	StatusRiskHold              StatusCode = 401 // The message was held for a risk review
+	StatusInvalidSenderId       StatusCode = 402 // The sender ID is not registered to your account
+	StatusInvalidPhoneNumber    StatusCode = 403 // The recipient's phone number is invalid
+	StatusUnsupportedNumberType StatusCode = 404 // The recipient's number type is not supported
+	StatusInsufficientBalance   StatusCode = 405 // The account balance is too low to send the message
+	StatusUserInBlacklist       StatusCode = 406 // The recipient opted out of messages from the sender
+	StatusCouldNotRoute         StatusCode = 407 // The message could not be routed to the recipient's network
+	StatusDoNotDisturbRejection StatusCode = 409 // The recipient's do not disturb settings rejected the message
+	StatusInternalServerError   StatusCode = 500 // Africa's Talking failed to process the message
+	StatusGatewayError          StatusCode = 501 // The telco gateway failed to process the message
+	StatusRejectedByGateway     StatusCode = 502 // The telco gateway rejected the message
+)
+
+// statusNames maps each documented status code to its name
+var statusNames = map[StatusCode]string{
+	StatusProcessed:             "Processed",
+	StatusSent:                  "Sent",
+	StatusQueued:                "Queued",
+	StatusRiskHold:              "RiskHold",
+	StatusInvalidSenderId:       "InvalidSenderId",
+	StatusInvalidPhoneNumber:    "InvalidPhoneNumber",
+	StatusUnsupportedNumberType: "UnsupportedNumberType",
+	StatusInsufficientBalance:   "InsufficientBalance",
+	StatusUserInBlacklist:       "UserInBlacklist",
+	StatusCouldNotRoute:         "CouldNotRoute",
+	StatusDoNotDisturbRejection: "DoNotDisturbRejection",
+	StatusInternalServerError:   "InternalServerError",
+	StatusGatewayError:          "GatewayError",
+	StatusRejectedByGateway:     "RejectedByGateway",
+}
+
+// String returns the documented name of the status code e.g "Sent", or the number for unknown codes
+func (s StatusCode) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// IsSuccess reports whether the message was accepted for delivery
+func (s StatusCode) IsSuccess() bool {
+	return s == StatusProcessed || s == StatusSent || s == StatusQueued
+}
+
+// IsPermanentFailure reports whether sending the same message to the recipient again will fail the same way.
+// Failures that are neither successful nor permanent (e.g InsufficientBalance or gateway errors) may succeed on retry
+func (s StatusCode) IsPermanentFailure() bool {
+	switch s {
+	case StatusInvalidSenderId,
+		StatusInvalidPhoneNumber,
+		StatusUnsupportedNumberType,
+		StatusUserInBlacklist,
+		StatusCouldNotRoute,
+		StatusDoNotDisturbRejection:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package sms
+
+import "testing"
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		code      StatusCode
+		name      string
+		success   bool
+		permanent bool
+	}{
+		{StatusSent, "Sent", true, false},
+		{StatusQueued, "Queued", true, false},
+		{StatusInvalidPhoneNumber, "InvalidPhoneNumber", false, true},
+		{StatusUserInBlacklist, "UserInBlacklist", false, true},
+		{StatusInsufficientBalance, "InsufficientBalance", false, false},
+		{StatusGatewayError, "GatewayError", false, false},
+		{StatusCode(999), "999", false, false},
+	}
+	for _, test := range tests {
+		if test.code.String() != test.name {
+			t.Fatalf("expected name='%s' got name='%s'", test.name, test.code.String())
+		}
+		if test.code.IsSuccess() != test.success || test.code.IsPermanentFailure() != test.permanent {
+			t.Fatalf("%s: unexpected classification", test.name)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
+)
+
+const (
+	checkoutTokenPath      = "/checkout/token/create"
+	subscriptionPath       = "/version1/subscription"
+	createSubscriptionPath = "/version1/subscription/create"
+	deleteSubscriptionPath = "/version1/subscription/delete"
+)
+
+// checkoutTokenCreated is the description of a successfully generated checkout token
+const checkoutTokenCreated = "Success"
+
+// SubscriptionRequest identifies a phone number's subscription to a premium short code and keyword
+type SubscriptionRequest struct {
+	ShortCode     string // ShortCode is the premium short code mapped to your account (required)
+	Keyword       string // Keyword is the premium keyword under the short code (required)
+	PhoneNumber   string // PhoneNumber is the subscriber's phone number "+2547XXXXXXXX" (required)
+	CheckoutToken string // CheckoutToken authorises creating the subscription, generated automatically when empty (optional)
+}
+
+// FetchSubscriptionsRequest represents the request for fetching the subscribers of a premium short code and keyword
+type FetchSubscriptionsRequest struct {
+	ShortCode      string // ShortCode is the premium short code mapped to your account (required)
+	Keyword        string // Keyword is the premium keyword under the short code (required)
+	LastReceivedId int64  // LastReceivedId is the id of the last subscription received, 0 fetches from the first subscription (optional)
+}
+
+// SubscriptionResponse represents the response after creating or deleting a subscription
+type SubscriptionResponse struct {
+	Status      string `json:"status"`      // Status of the request e.g "Success" or "Failed"
+	Description string `json:"description"` // Description of the status e.g "Waiting for user input"
+}
+
+// Subscription represents a phone number subscribed to a premium short code and keyword
+type Subscription struct {
+	Id          int64  `json:"id"`          // Id is the identifier of the subscription, used as LastReceivedId when fetching the next page
+	PhoneNumber string `json:"phoneNumber"` // PhoneNumber is the subscriber's phone number
+	Date        string `json:"date"`        // Date is when the subscription was created
+}
+
+// CheckoutTokenResponse represents the response after generating a checkout token
+type CheckoutTokenResponse struct {
+	Description string `json:"description"` // Description of the status e.g "Success"
+	Token       string `json:"token"`       // Token to be passed as SubscriptionRequest.CheckoutToken
+}
+
+// send sends req to endpoint, see core.Endpoint, and decodes the JSON response into v
+func (c *Client) send(req *http.Request, endpoint string, v interface{}) error {
+	setHeaders(req, c.ApiKey)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := core.CheckResponse(resp); err != nil {
+		return err
+	}
+	return core.DecodeJSON(resp, v)
+}
+
+// postForm posts the form encoded data to path on host and decodes the JSON response into v
+func (c *Client) postForm(ctx context.Context, host core.Host, path string, data url.Values, v interface{}) error {
+	url := core.ResolveURL(c.Endpoints, host, c.IsSandbox, path)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return err
+	}
+	return c.send(req, core.Endpoint(host, path), v)
+}
+
+// normalizeNumber converts number to E.164 when Region is set, returning it unchanged otherwise
+func (c *Client) normalizeNumber(number string) (string, error) {
+	if c.Region == "" {
+		return number, nil
+	}
+	return phonenumber.Normalize(number, c.Region)
+}
+
+/*
+CreateCheckoutToken generates a checkout token authorising a premium subscription for phoneNumber
+
+API Reference: https://developers.africastalking.com/docs/sms/premium_subscriptions/create
+*/
+func (c *Client) CreateCheckoutToken(ctx context.Context, phoneNumber string) (CheckoutTokenResponse, error) {
+	phoneNumber, err := c.normalizeNumber(phoneNumber)
+	if err != nil {
+		return CheckoutTokenResponse{}, err
+	}
+	res := CheckoutTokenResponse{}
+	data := url.Values{"phoneNumber": {phoneNumber}}
+	if err := c.postForm(ctx, core.HostAPI, checkoutTokenPath, data, &res); err != nil {
+		return CheckoutTokenResponse{}, err
+	}
+	return res, nil
+}
+
+/*
+CreateSubscription subscribes a phone number to a premium short code and keyword.
+A checkout token is generated first when the request does not carry one.
+
+API Reference: https://developers.africastalking.com/docs/sms/premium_subscriptions/create
+*/
+func (c *Client) CreateSubscription(ctx context.Context, request *SubscriptionRequest) (SubscriptionResponse, error) {
+	phoneNumber, err := c.normalizeNumber(request.PhoneNumber)
+	if err != nil {
+		return SubscriptionResponse{}, err
+	}
+	checkoutToken := request.CheckoutToken
+	if checkoutToken == "" {
+		token, err := c.CreateCheckoutToken(ctx, phoneNumber)
+		if err != nil {
+			return SubscriptionResponse{}, err
+		}
+		if token.Description != checkoutTokenCreated || token.Token == "" {
+			return SubscriptionResponse{Status: "Failed", Description: token.Description}, nil
+		}
+		checkoutToken = token.Token
+	}
+	data := url.Values{
+		"username":      {c.Username},
+		"shortCode":     {request.ShortCode},
+		"keyword":       {request.Keyword},
+		"phoneNumber":   {phoneNumber},
+		"checkoutToken": {checkoutToken},
+	}
+	res := SubscriptionResponse{}
+	if err := c.postForm(ctx, core.HostContent, createSubscriptionPath, data, &res); err != nil {
+		return SubscriptionResponse{}, err
+	}
+	return res, nil
+}
+
+/*
+DeleteSubscription unsubscribes a phone number from a premium short code and keyword
+
+API Reference: https://developers.africastalking.com/docs/sms/premium_subscriptions/delete
+*/
+func (c *Client) DeleteSubscription(ctx context.Context, request *SubscriptionRequest) (SubscriptionResponse, error) {
+	phoneNumber, err := c.normalizeNumber(request.PhoneNumber)
+	if err != nil {
+		return SubscriptionResponse{}, err
+	}
+	data := url.Values{
+		"username":    {c.Username},
+		"shortCode":   {request.ShortCode},
+		"keyword":     {request.Keyword},
+		"phoneNumber": {phoneNumber},
+	}
+	res := SubscriptionResponse{}
+	if err := c.postForm(ctx, core.HostContent, deleteSubscriptionPath, data, &res); err != nil {
+		return SubscriptionResponse{}, err
+	}
+	return res, nil
+}
+
+/*
+FetchSubscriptions fetches a page of subscribers to a premium short code and keyword received after request.LastReceivedId.
+An empty result means there are no newer subscriptions.
+
+API Reference: https://developers.africastalking.com/docs/sms/premium_subscriptions/fetch
+*/
+func (c *Client) FetchSubscriptions(ctx context.Context, request *FetchSubscriptionsRequest) ([]Subscription, error) {
+	query := url.Values{
+		"username":       {c.Username},
+		"shortCode":      {request.ShortCode},
+		"keyword":        {request.Keyword},
+		"lastReceivedId": {strconv.FormatInt(request.LastReceivedId, 10)},
+	}
+	url := core.ResolveURL(c.Endpoints, core.HostContent, c.IsSandbox, subscriptionPath) + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := struct {
+		Responses []Subscription `json:"responses"`
+	}{}
+	if err := c.send(req, core.Endpoint(core.HostContent, subscriptionPath), &res); err != nil {
+		return nil, err
+	}
+	if res.Responses == nil {
+		return []Subscription{}, nil
+	}
+	return res.Responses, nil
+}
+
+// SubscriptionIterator walks every subscriber to a premium short code and keyword page by page
+type SubscriptionIterator struct {
+	pager pager[Subscription]
+}
+
+// Subscriptions returns an iterator over the subscribers to the short code and keyword in request
+func (c *Client) Subscriptions(request FetchSubscriptionsRequest) *SubscriptionIterator {
+	return &SubscriptionIterator{pager: pager[Subscription]{
+		fetch: func(ctx context.Context, lastReceivedId int64) ([]Subscription, error) {
+			request.LastReceivedId = lastReceivedId
+			return c.FetchSubscriptions(ctx, &request)
+		},
+		id:             func(subscription Subscription) int64 { return subscription.Id },
+		lastReceivedId: request.LastReceivedId,
+	}}
+}
+
+// Next advances to the next subscription, fetching a new page when needed.
+// It returns false when there are no more subscriptions or an error occurred
+func (it *SubscriptionIterator) Next(ctx context.Context) bool {
+	return it.pager.next(ctx)
+}
+
+// Subscription returns the current subscription
+func (it *SubscriptionIterator) Subscription() Subscription {
+	return it.pager.current
+}
+
+// LastReceivedId returns the id of the newest subscription seen so far, to resume iteration later
+func (it *SubscriptionIterator) LastReceivedId() int64 {
+	return it.pager.lastReceivedId
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *SubscriptionIterator) Err() error {
+	return it.pager.err
+}
@@ -0,0 +1,84 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		r.ParseForm()
+		if r.PostForm.Get("phoneNumber") != "+254711000000" {
+			t.Errorf("%s: expected the normalised phoneNumber got '%s'", r.URL.Path, r.PostForm.Get("phoneNumber"))
+		}
+		switch r.URL.Path {
+		case "/checkout/token/create":
+			fmt.Fprint(w, `{"description":"Success","token":"CkTkn_1"}`)
+		case "/version1/subscription/create":
+			if r.PostForm.Get("checkoutToken") != "CkTkn_1" || r.PostForm.Get("keyword") != "news" {
+				t.Errorf("unexpected subscription form %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"status":"Success","description":"Waiting for user input"}`)
+		case "/version1/subscription/delete":
+			fmt.Fprint(w, `{"status":"Success","description":"Succeeded"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Region: "KE"}
+	request := &SubscriptionRequest{ShortCode: "28901", Keyword: "news", PhoneNumber: "0711 000 000"}
+
+	response, err := client.CreateSubscription(context.Background(), request)
+	if err != nil {
+		t.Fatalf("create subscription failed: %s", err.Error())
+	}
+	if response.Description != "Waiting for user input" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	response, err = client.DeleteSubscription(context.Background(), request)
+	if err != nil {
+		t.Fatalf("delete subscription failed: %s", err.Error())
+	}
+	if response.Status != "Success" {
+		t.Fatalf("expected status='Success' got status='%s'", response.Status)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expected checkout token, create and delete requests got %v", paths)
+	}
+}
+
+func TestSubscriptionIterator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("shortCode") != "28901" || query.Get("keyword") != "news" {
+			t.Errorf("unexpected query %v", query)
+		}
+		if query.Get("lastReceivedId") == "0" {
+			fmt.Fprint(w, `{"responses":[{"id":1,"phoneNumber":"+254711000000","date":"2017-12-18 13:15:03"},{"id":2,"phoneNumber":"+254711000001"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"responses":[]}`)
+	}))
+	defer server.Close()
+
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+	it := client.Subscriptions(FetchSubscriptionsRequest{ShortCode: "28901", Keyword: "news"})
+	phoneNumbers := []string{}
+	for it.Next(context.Background()) {
+		phoneNumbers = append(phoneNumbers, it.Subscription().PhoneNumber)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %s", err.Error())
+	}
+	if len(phoneNumbers) != 2 || it.LastReceivedId() != 2 {
+		t.Fatalf("expected 2 subscriptions got %v", phoneNumbers)
+	}
+}
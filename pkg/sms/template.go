@@ -0,0 +1,166 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
+)
+
+// ErrRecipientMissing is reported for a recipient that the API left out of its response
+var ErrRecipientMissing = errors.New("sms: recipient missing from response")
+
+// TemplateRecipient is a recipient of a templated message together with the data used to personalise it
+type TemplateRecipient struct {
+	PhoneNumber string      // PhoneNumber is the recipient's phone number "+2547XXXXXXXX" (required)
+	Data        interface{} // Data is passed to the template when rendering this recipient's message e.g map[string]string{"FirstName": "Jane"}
+}
+
+// TemplateRequest represents a bulk SMS personalised for each recipient through a text/template
+type TemplateRequest struct {
+	Template      *template.Template  // Template renders each recipient's message e.g "Hello {{.FirstName}}" (required)
+	Recipients    []TemplateRecipient // Recipients and the data their messages are rendered with (required)
+	From          string              // From is your registered short code or alphanumerics, defaults to AFRICASTKNG (optional)
+	BulkSMSMode   bool                // BulkSMSMode determines who gets billed for a message sent (optional)
+	Enqueue       bool                // If enabled, the API will store the messages in a queue and send them out asynchronously (optional)
+	RetryDuration time.Duration       // RetryDuration specifies the number of hours the message should be retried in case it's not delivered (optional)
+	Batch         BatchOptions        // Batch controls how recipients sharing the same message are chunked (optional)
+}
+
+// TemplateResult is the outcome of a templated message for an individual recipient
+type TemplateResult struct {
+	PhoneNumber string    // PhoneNumber of the recipient
+	Message     string    // Message rendered for the recipient
+	Recipient   Recipient // Recipient is the status returned by the API for this phone number
+	Suppressed  bool      // Suppressed is set when Client.Suppressions filtered the recipient out, no message was sent
+	Err         error     // Err is set when the message could not be rendered or sent
+}
+
+/*
+SendTemplate renders request.Template for every recipient, groups recipients whose messages render to
+identical text into shared bulk requests and sends the remaining messages individually. Up to
+request.Batch.Concurrency groups are sent at once, each split into chunks as described by SendBulkBatched.
+
+The results are returned in the order of request.Recipients. When any recipient fails the error joins the
+distinct failures, which are also available in each TemplateResult.Err. Recipients filtered out by
+Client.Suppressions are marked as TemplateResult.Suppressed without an error.
+*/
+func (c *Client) SendTemplate(ctx context.Context, request *TemplateRequest) ([]TemplateResult, error) {
+	results := make([]TemplateResult, len(request.Recipients))
+	groups := map[string][]int{}
+	order := []string{}
+	for i, recipient := range request.Recipients {
+		results[i].PhoneNumber = recipient.PhoneNumber
+		message := strings.Builder{}
+		if err := request.Template.Execute(&message, recipient.Data); err != nil {
+			results[i].Err = fmt.Errorf("sms: rendering message for %s: %w", recipient.PhoneNumber, err)
+			continue
+		}
+		text := message.String()
+		results[i].Message = text
+		if _, ok := groups[text]; !ok {
+			order = append(order, text)
+		}
+		groups[text] = append(groups[text], i)
+	}
+
+	concurrency := request.Batch.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	storeErrs := make([]error, len(order))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for g, text := range order {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for _, i := range groups[text] {
+				results[i].Err = ctx.Err()
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(g int, text string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			storeErrs[g] = c.sendTemplateGroup(ctx, request, text, groups[text], results)
+		}(g, text)
+	}
+	wg.Wait()
+
+	errs := []error{}
+	seen := map[error]bool{}
+	for _, result := range results {
+		if result.Err != nil && !seen[result.Err] {
+			seen[result.Err] = true
+			errs = append(errs, result.Err)
+		}
+	}
+	return results, errors.Join(append(errs, storeErrs...)...)
+}
+
+// sendTemplateGroup sends text to the recipients at indexes and records each recipient's outcome in results,
+// returning the error of a suppression store failure that is not tied to a single recipient
+func (c *Client) sendTemplateGroup(ctx context.Context, request *TemplateRequest, text string, indexes []int, results []TemplateResult) error {
+	to := make([]string, len(indexes))
+	for j, i := range indexes {
+		to[j] = results[i].PhoneNumber
+	}
+	batch, err := c.SendBulkBatched(ctx, &BulkRequest{
+		To:            to,
+		Message:       text,
+		From:          request.From,
+		BulkSMSMode:   request.BulkSMSMode,
+		Enqueue:       request.Enqueue,
+		RetryDuration: request.RetryDuration,
+	}, request.Batch)
+
+	// The response lists the numbers as sent, normalised when Client.Region is set
+	numbers, normalizeErr := phonenumber.NormalizeAll(to, c.Region)
+	if normalizeErr != nil {
+		numbers = to
+	}
+	statuses := map[string]Recipient{}
+	for _, recipient := range batch.Recipients {
+		statuses[recipient.Number] = recipient
+	}
+	skipped := map[string]bool{}
+	for _, number := range batch.Skipped {
+		skipped[number] = true
+	}
+	failed := map[string]error{}
+	for _, chunkErr := range batch.Errors {
+		for _, number := range chunkErr.To {
+			failed[number] = chunkErr.Err
+		}
+	}
+	for j, i := range indexes {
+		number := results[i].PhoneNumber
+		if chunkErr, ok := failed[number]; ok {
+			results[i].Err = chunkErr
+			continue
+		}
+		recipient, ok := statuses[numbers[j]]
+		switch {
+		case ok:
+			results[i].Recipient = recipient
+		case skipped[numbers[j]]:
+			results[i].Suppressed = true
+		case err != nil && len(batch.Responses) == 0:
+			// The batch failed before any chunk was sent
+			results[i].Err = err
+		default:
+			results[i].Err = fmt.Errorf("%w: %s", ErrRecipientMissing, number)
+		}
+	}
+	if errors.Is(err, ErrSuppressionStore) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+)
+
+func TestSendTemplate(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		to := strings.Split(r.PostForm.Get("to"), ",")
+		mu.Lock()
+		requests[r.PostForm.Get("message")] = to
+		mu.Unlock()
+		recipients := []string{}
+		for _, number := range to {
+			recipients = append(recipients, fmt.Sprintf(`{"statusCode":101,"number":"%s","status":"Success","messageId":"id-%s"}`, number, number))
+		}
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[%s]}}`, strings.Join(recipients, ","))
+	}))
+	defer server.Close()
+
+	tmpl := template.Must(template.New("balance").Option("missingkey=error").Parse("Hello {{.FirstName}}, your balance is {{.Balance}}"))
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+	results, err := client.SendTemplate(context.Background(), &TemplateRequest{
+		Template: tmpl,
+		Recipients: []TemplateRecipient{
+			{PhoneNumber: "+254700000001", Data: map[string]string{"FirstName": "Jane", "Balance": "KES 10"}},
+			{PhoneNumber: "+254700000002", Data: map[string]string{"FirstName": "John", "Balance": "KES 20"}},
+			{PhoneNumber: "+254700000003", Data: map[string]string{"FirstName": "Jane", "Balance": "KES 10"}},
+			{PhoneNumber: "+254700000004", Data: map[string]string{"Balance": "KES 10"}},
+		},
+	})
+
+	if err == nil || results[3].Err == nil {
+		t.Fatalf("expected a render error for the recipient without a FirstName")
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 bulk requests got %v", requests)
+	}
+	shared := requests["Hello Jane, your balance is KES 10"]
+	if len(shared) != 2 || shared[0] != "+254700000001" || shared[1] != "+254700000003" {
+		t.Fatalf("expected identical messages to share a request got %v", shared)
+	}
+	for _, result := range results[:3] {
+		if result.Err != nil || result.Recipient.MessageId != "id-"+result.PhoneNumber {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	}
+	if results[1].Message != "Hello John, your balance is KES 20" {
+		t.Fatalf("unexpected message '%s'", results[1].Message)
+	}
+}
+
+func TestSendTemplateReportsEveryFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[{"statusCode":101,"number":"+254700000001","status":"Success","messageId":"id-1"}]}}`)
+	}))
+	defer server.Close()
+
+	tmpl := template.Must(template.New("hello").Parse("Hello {{.}}"))
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+	results, err := client.SendTemplate(context.Background(), &TemplateRequest{
+		Template: tmpl,
+		Recipients: []TemplateRecipient{
+			{PhoneNumber: "+254700000001", Data: "Jane"},
+			{PhoneNumber: "+254700000002", Data: "Jane"},
+		},
+	})
+	if results[0].Err != nil || !errors.Is(results[1].Err, ErrRecipientMissing) || !errors.Is(err, ErrRecipientMissing) {
+		t.Fatalf("expected the missing recipient to be reported got %v", err)
+	}
+
+	client.MaxSegments = 1
+	results, err = client.SendTemplate(context.Background(), &TemplateRequest{
+		Template:   tmpl,
+		Recipients: []TemplateRecipient{{PhoneNumber: "+254700000001", Data: strings.Repeat("👋", 100)}},
+	})
+	var limitErr *SegmentLimitError
+	if !errors.As(err, &limitErr) || !errors.As(results[0].Err, &limitErr) {
+		t.Fatalf("expected the batch error to be reported got %v", err)
+	}
+}
+
+func TestSendTemplateNormalizesNumbers(t *testing.T) {
+	sends := map[string]int{}
+	server := outboxServer(t, map[string]int{}, sends)
+	defer server.Close()
+
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Region: "KE"}
+	results, err := client.SendTemplate(context.Background(), &TemplateRequest{
+		Template:   template.Must(template.New("hello").Parse("Hello {{.}}")),
+		Recipients: []TemplateRecipient{{PhoneNumber: "0712 345 678", Data: "Jane"}},
+	})
+	if err != nil || results[0].PhoneNumber != "0712 345 678" || results[0].Recipient.MessageId != "id-+254712345678" {
+		t.Fatalf("expected the normalised number to be matched got %+v err=%v", results, err)
+	}
+}
+
+func TestSendTemplateSendsGroupsConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	overlapped := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+			if maxInFlight == 2 {
+				close(overlapped)
+			}
+		}
+		mu.Unlock()
+		// Hold the request until another one is in flight
+		select {
+		case <-overlapped:
+		case <-time.After(time.Second):
+		}
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"Sent","Recipients":[{"statusCode":101,"number":"%s","status":"Success"}]}}`, r.PostForm.Get("to"))
+	}))
+	defer server.Close()
+
+	recipients := []TemplateRecipient{}
+	for i := 1; i <= 6; i++ {
+		recipients = append(recipients, TemplateRecipient{PhoneNumber: fmt.Sprintf("+25470000000%d", i), Data: i})
+	}
+	client := &Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL)}
+	_, err := client.SendTemplate(context.Background(), &TemplateRequest{
+		Template:   template.Must(template.New("balance").Parse("Your balance is KES {{.}}")),
+		Recipients: recipients,
+		Batch:      BatchOptions{Concurrency: 2},
+	})
+	if err != nil {
+		t.Fatalf("send template failed: %s", err.Error())
+	}
+	if maxInFlight != 2 {
+		t.Fatalf("expected personalised messages to be sent 2 at a time got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,53 @@
+package sms
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxCallbackBytes bounds the size of a callback body read by the webhook handlers
+const maxCallbackBytes = 64 << 10
+
+// parseCallback parses the form encoded body of an Africa's Talking callback,
+// writing a 4xx response and returning false when the request is malformed
+func parseCallback(w http.ResponseWriter, r *http.Request, required ...string) (url.Values, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxCallbackBytes)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "malformed callback: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	for _, field := range required {
+		if r.PostForm.Get(field) == "" {
+			http.Error(w, "malformed callback: missing "+field, http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return r.PostForm, true
+}
+
+// callbackTimeLayouts are the date formats found in Africa's Talking callbacks
+var callbackTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05"}
+
+// parseCallbackTime parses a callback date, in UTC unless it carries a zone, like InboundMessage.Date
+func parseCallbackTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range callbackTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// callbackFailed responds with a generic 500 so Africa's Talking retries the callback,
+// keeping the handler's error out of the response
+func callbackFailed(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
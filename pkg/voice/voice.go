@@ -7,27 +7,31 @@ package voice
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
-	"io"
+	"context"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
+	"github.com/edwinwalela/africastalking-go/pkg/phonenumber"
 )
 
 const (
-	callLiveURL        = "https://voice.africastalking.com/call"
-	callSandboxURL     = "https://voice.sandbox.africastalking.com/call"
-	transferLiveUrl    = "https://voice.africastalking.com/callTransfer"
-	transferSandboxURL = "https://voice.sandbox.africastalking.com/callTransfer"
+	callPath     = "/call"
+	transferPath = "/callTransfer"
 )
 
-// Client represents the HTTP client responsible for communicating with Africa's Talking API
+// Client represents the HTTP client responsible for communicating with Africa's Talking API.
+// A Client is safe for concurrent use by multiple goroutines
 type Client struct {
-	ApiKey    string       // API Key provided by Africa's talking
-	Username  string       // Your Africa's talking application username
-	IsSandbox bool         // IsSandbox specifies whether to use sandbox or live environment
-	client    *http.Client // HTTP client for making requests to Africa's Talking API
+	ApiKey    string            // API Key provided by Africa's talking
+	Username  string            // Your Africa's talking application username
+	IsSandbox bool              // IsSandbox specifies whether to use sandbox or live environment
+	Client    *http.Client      // HTTP client for making requests to Africa's Talking API. Defaults to core.DefaultHTTPClient
+	Endpoints core.Resolver     // Endpoints resolves API URLs, defaults to Africa's Talking's hosts for the environment
+	Retry     *core.RetryPolicy // Retry controls how transient failures are retried, nil disables retries
+	Limiter   *core.RateLimiter // Limiter throttles requests client-side, nil disables rate limiting
+	Region    string            // Region normalises phone numbers to E.164 before sending, reading local numbers as numbers of this ISO country code e.g "KE". Empty sends numbers as given
 }
 
 // CallRequest represents the body to be sent to the Voice API when initiating a call
@@ -47,21 +51,21 @@ type CallTransferRequest struct {
 
 // Recipient represents the status of the call of an individual user specified in Request
 type Recipient struct {
-	PhoneNumber string // Recipient's phone number
-	Status      string // Status of the request:e.g "Queued","InvalidPhoneNumber","DestinationNotSupported","Insufficient Credit"
-	SessionId   string // A unique identifier for the request associated to this phone number
+	PhoneNumber string `json:"phoneNumber"` // Recipient's phone number
+	Status      string `json:"status"`      // Status of the request:e.g "Queued","InvalidPhoneNumber","DestinationNotSupported","Insufficient Credit"
+	SessionId   string `json:"sessionId"`   // A unique identifier for the request associated to this phone number
 }
 
 // CallResponse represents the response after initiating a Call
 type CallResponse struct {
-	Recipients   []Recipient // List of recipients and their status
-	ErrorMessage string      // Error message if the entire request was rejected by the API
+	Recipients   []Recipient `json:"entries"`      // List of recipients and their status
+	ErrorMessage string      `json:"errorMessage"` // Error message if the entire request was rejected by the API
 }
 
-// CallTransferResponse represents the
+// CallTransferResponse represents the response after transferring a call
 type CallTransferResponse struct {
-	Status       string // Status of the call transfer request. 'Success' or 'Aborted'
-	ErrorMessage string // Reason why the transfer was aborted
+	Status       string `json:"status"`       // Status of the call transfer request. 'Success' or 'Aborted'
+	ErrorMessage string `json:"errorMessage"` // Reason why the transfer was aborted
 }
 
 // setHeaders configures required headers for the HTTP request to Africa's Talking API
@@ -86,6 +90,7 @@ func getCallTransferRequestBody(request *CallTransferRequest, username string) u
 	return url.Values{
 		"username":     {username},
 		"sessionId":    {request.SessionId},
+		"phoneNumber":  {request.PhoneNumber},
 		"callLeg":      {request.CallLeg},
 		"holdMusicUrl": {request.HoldMusicUrl},
 	}
@@ -93,42 +98,23 @@ func getCallTransferRequestBody(request *CallTransferRequest, username string) u
 
 // formatCallResponse maps response from Africa's Talking call API to the internal Response type
 func formatCallResponse(response *http.Response) (CallResponse, error) {
-	res := make(map[string]interface{})
-	decoder := json.NewDecoder(response.Body)
-	if err := decoder.Decode(&res); err != nil {
+	res := CallResponse{}
+	if err := core.DecodeJSON(response, &res); err != nil {
 		return CallResponse{}, err
 	}
-
-	entries := res["entries"].([]interface{})
-	recipients := []Recipient{}
-
-	for _, entry := range entries {
-		data := entry.(map[string]interface{})
-		recipient := Recipient{
-			PhoneNumber: data["phoneNumber"].(string),
-			Status:      data["status"].(string),
-			SessionId:   data["sessionId"].(string),
-		}
-		recipients = append(recipients, recipient)
+	if res.Recipients == nil {
+		res.Recipients = []Recipient{}
 	}
-
-	return CallResponse{
-		ErrorMessage: res["errorMessage"].(string),
-		Recipients:   recipients,
-	}, nil
+	return res, nil
 }
 
 // formatCallTransferResponse maps response from Africa's Talking call transfer API to the internal Response type
 func formatCallTransferResponse(response *http.Response) (CallTransferResponse, error) {
-	res := make(map[string]string)
-	decoder := json.NewDecoder(response.Body)
-	if err := decoder.Decode(&res); err != nil {
+	res := CallTransferResponse{}
+	if err := core.DecodeJSON(response, &res); err != nil {
 		return CallTransferResponse{}, err
 	}
-	return CallTransferResponse{
-		Status:       res["status"],
-		ErrorMessage: res["errorMessage"],
-	}, nil
+	return res, nil
 }
 
 /*
@@ -139,27 +125,32 @@ When the call is picked, Africa's Talking will call your callback url.
 API Reference: https://developers.africastalking.com/docs/voice/handle_calls
 */
 func (c *Client) Call(request *CallRequest) (CallResponse, error) {
-	c.client = &http.Client{}
-	data := getCallRequestBody(request, c.Username)
-	url := callLiveURL
-	if c.IsSandbox {
-		url = callSandboxURL
+	return c.CallWithContext(context.Background(), request)
+}
+
+// CallWithContext is like Call but aborts the request when ctx is cancelled or its deadline expires
+func (c *Client) CallWithContext(ctx context.Context, request *CallRequest) (CallResponse, error) {
+	to, err := phonenumber.NormalizeAll(request.To, c.Region)
+	if err != nil {
+		return CallResponse{}, err
+	}
+	normalized := *request
+	normalized.To = to
+	data := getCallRequestBody(&normalized, c.Username)
+	url := core.ResolveURL(c.Endpoints, core.HostVoice, c.IsSandbox, callPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return CallResponse{}, err
 	}
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data.Encode())))
 	setHeaders(req, c.ApiKey)
-	resp, err := c.client.Do(req)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostVoice, callPath))
 	if err != nil {
 		return CallResponse{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return CallResponse{}, err
-		}
-		res := string(bodyBytes)
-		return CallResponse{}, errors.New(res)
+	if err := core.CheckResponse(resp); err != nil {
+		return CallResponse{}, err
 	}
 
 	return formatCallResponse(resp)
@@ -167,28 +158,33 @@ func (c *Client) Call(request *CallRequest) (CallResponse, error) {
 
 // Transfer transfers a call to another number.  Only works in live environment
 func (c *Client) Transfer(request *CallTransferRequest) (CallTransferResponse, error) {
-	c.client = &http.Client{}
-	data := getCallTransferRequestBody(request, c.Username)
-	url := transferLiveUrl
-	if c.IsSandbox {
-		url = transferSandboxURL
+	return c.TransferWithContext(context.Background(), request)
+}
+
+// TransferWithContext is like Transfer but aborts the request when ctx is cancelled or its deadline expires
+func (c *Client) TransferWithContext(ctx context.Context, request *CallTransferRequest) (CallTransferResponse, error) {
+	phoneNumber, err := phonenumber.NormalizeAll([]string{request.PhoneNumber}, c.Region)
+	if err != nil {
+		return CallTransferResponse{}, err
+	}
+	normalized := *request
+	normalized.PhoneNumber = phoneNumber[0]
+	data := getCallTransferRequestBody(&normalized, c.Username)
+	url := core.ResolveURL(c.Endpoints, core.HostVoice, c.IsSandbox, transferPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer([]byte(data.Encode())))
+	if err != nil {
+		return CallTransferResponse{}, err
 	}
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer([]byte(data.Encode())))
 	setHeaders(req, c.ApiKey)
-	resp, err := c.client.Do(req)
+	resp, err := core.Do(core.HTTPClient(c.Client), c.Retry, c.Limiter, req, core.Endpoint(core.HostVoice, transferPath))
 
 	if err != nil {
 		return CallTransferResponse{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return CallTransferResponse{}, err
-		}
-		res := string(bodyBytes)
-		return CallTransferResponse{}, errors.New(res)
+	if err := core.CheckResponse(resp); err != nil {
+		return CallTransferResponse{}, err
 	}
 
 	return formatCallTransferResponse(resp)
@@ -1,8 +1,14 @@
 package voice
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/edwinwalela/africastalking-go/pkg/core"
 )
 
 func TestCall(t *testing.T) {
@@ -26,3 +32,41 @@ func TestCall(t *testing.T) {
 		t.Fatalf("expected errorMessage='none' got errorMessage='%s'", response.ErrorMessage)
 	}
 }
+
+func TestFormatCallResponse(t *testing.T) {
+	body := `{"entries":[{"phoneNumber":"+254700000001","status":"Queued","sessionId":"ATVId_1"}],"errorMessage":"None"}`
+	response, err := formatCallResponse(&http.Response{Body: io.NopCloser(strings.NewReader(body))})
+	if err != nil {
+		t.Fatalf("failed to format call response: %s", err.Error())
+	}
+	if response.Recipients[0].SessionId != "ATVId_1" {
+		t.Fatalf("expected sessionId='ATVId_1' got sessionId='%s'", response.Recipients[0].SessionId)
+	}
+
+	// An error payload has no entries and must not panic
+	response, err = formatCallResponse(&http.Response{Body: io.NopCloser(strings.NewReader(`{"errorMessage":"Invalid callerId"}`))})
+	if err != nil {
+		t.Fatalf("failed to format call response: %s", err.Error())
+	}
+	if len(response.Recipients) != 0 || response.ErrorMessage != "Invalid callerId" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+}
+
+func TestTransferNormalizesNumber(t *testing.T) {
+	var phoneNumber string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		phoneNumber = r.PostForm.Get("phoneNumber")
+		io.WriteString(w, `{"status":"Success","errorMessage":"None"}`)
+	}))
+	defer server.Close()
+
+	client := Client{Username: "sandbox", Endpoints: core.AllHosts(server.URL), Region: "KE"}
+	if _, err := client.Transfer(&CallTransferRequest{SessionId: "ATVId_1", PhoneNumber: "0712 345 678"}); err != nil {
+		t.Fatalf("failed to transfer call: %s", err.Error())
+	}
+	if phoneNumber != "+254712345678" {
+		t.Fatalf("expected phoneNumber='+254712345678' got phoneNumber='%s'", phoneNumber)
+	}
+}